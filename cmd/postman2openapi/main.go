@@ -0,0 +1,90 @@
+// Command postman2openapi converts Postman collections to OpenAPI 3.1.
+//
+//	postman2openapi [-format yaml|json] [-o dir] collection.json|archive.zip [environment.json...]
+//
+// Environments given after the collection resolve its {{variables}}. The
+// result is printed, or written to -o when set or when there are several
+// collections.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/arifth/botthie/usecase"
+	"github.com/arifth/botthie/util"
+)
+
+func main() {
+	format := flag.String("format", "yaml", "output format, yaml or json")
+	out := flag.String("o", "", "directory the OpenAPI files are written to")
+	flag.Parse()
+	if flag.NArg() < 1 || (*format != "yaml" && *format != "json") {
+		fmt.Fprintln(os.Stderr, "usage: postman2openapi [-format yaml|json] [-o dir] collection.json|archive.zip [environment.json...]")
+		os.Exit(2)
+	}
+
+	input := flag.Arg(0)
+	data, err := os.ReadFile(input)
+	if err != nil {
+		fail(err)
+	}
+	// environments are bundled with the collection as the bot does for
+	// uploaded archives
+	if flag.NArg() > 1 {
+		files := map[string][]byte{filepath.Base(input): data}
+		if filepath.Ext(input) == ".zip" {
+			if files, err = util.ReadZip(data); err != nil {
+				fail(err)
+			}
+		}
+		for _, env := range flag.Args()[1:] {
+			content, err := os.ReadFile(env)
+			if err != nil {
+				fail(err)
+			}
+			files[filepath.Base(env)] = content
+		}
+		if data, err = util.WriteZip(files); err != nil {
+			fail(err)
+		}
+		input += ".zip"
+	}
+
+	files, err := usecase.ConvertPostmanToOpenAPI(filepath.Base(input), data, *format)
+	if err != nil {
+		fail(err)
+	}
+	var names []string
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	if *out == "" && len(files) == 1 {
+		os.Stdout.Write(files[names[0]])
+		return
+	}
+	dir := *out
+	if dir == "" {
+		dir = "."
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		fail(err)
+	}
+	for _, name := range names {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, files[name], 0o644); err != nil {
+			fail(err)
+		}
+		fmt.Println(path)
+	}
+}
+
+func fail(err error) {
+	fmt.Fprintln(os.Stderr, err)
+	os.Exit(1)
+}
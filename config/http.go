@@ -16,6 +16,10 @@ type Config struct {
 	RetryWaitTime time.Duration
 	RetryMaxWait  time.Duration
 	Debug         bool
+	// Username and Password replace the USERNAME and PASSWORD basic auth
+	// from the environment when set
+	Username string
+	Password string
 }
 
 // Client wraps the resty client with configuration
@@ -42,11 +46,13 @@ func NewClient(cfg *Config) *Client {
 
 	// Create resty client
 	client := resty.New()
-	username := os.Getenv("USERNAME")
-	password := os.Getenv("PASSWORD")
+	username, password := cfg.Username, cfg.Password
+	if username == "" {
+		username = os.Getenv("USERNAME")
+		password = os.Getenv("PASSWORD")
+	}
 	client.SetBasicAuth(username, password)
 	client.SetHeader("Content-Type", "application/json")
-	client.SetDebug(true)
 
 	// Configure base URL
 	if cfg.BaseURL != "" {
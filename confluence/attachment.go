@@ -0,0 +1,35 @@
+package confluence
+
+import (
+	"bytes"
+
+	"github.com/arifth/botthie/model"
+	"github.com/go-resty/resty/v2"
+)
+
+// Attachments returns every attachment of a page
+func (c *Client) Attachments(pageID string) ([]model.Content, error) {
+	return collect[model.Content](c, "list the attachments of page", pageID, contentPath(pageID, "child", "attachment"), expandQuery([]string{"version"}))
+}
+
+// Attach uploads a file to a page with a comment. An attachment of the same
+// name gets a new version, so uploading a file again replaces it
+func (c *Client) Attach(pageID string, name string, comment string, data []byte) (model.Content, error) {
+	req := c.clt.NewRequest().
+		SetHeader("X-Atlassian-Token", "no-check").
+		SetFileReader("file", name, bytes.NewReader(data)).
+		SetMultipartFormData(map[string]string{"minorEdit": "true", "comment": comment})
+	var page model.ResultPage[model.Content]
+	if err := c.do("attach", name, req, resty.MethodPut, contentPath(pageID, "child", "attachment"), &page); err != nil {
+		return model.Content{}, err
+	}
+	if len(page.Results) == 0 {
+		return model.Content{}, nil
+	}
+	return page.Results[0], nil
+}
+
+// DeleteAttachment moves an attachment to the trash
+func (c *Client) DeleteAttachment(id string) error {
+	return c.do("remove attachment", id, c.clt.NewRequest(), resty.MethodDelete, contentPath(id), nil)
+}
@@ -0,0 +1,89 @@
+package confluence
+
+import (
+	"encoding/json"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/arifth/botthie/config"
+	"github.com/arifth/botthie/model"
+	"github.com/go-resty/resty/v2"
+)
+
+// pageLimit is the number of results requested per page of a listing
+const pageLimit = 100
+
+// Client calls the REST API of Confluence through a config.Client, which
+// carries the base URL and the authentication. Root is the path of the REST
+// API below the base URL: empty when the base URL already points at it, as
+// BASE_URL does for Confluence Server, /rest/api for Confluence Cloud
+type Client struct {
+	clt  *config.Client
+	root string
+}
+
+// NewClient returns a Client sending its requests to root below the base URL
+// of clt
+func NewClient(clt *config.Client, root string) *Client {
+	return &Client{clt: clt, root: strings.TrimSuffix(root, "/")}
+}
+
+// do sends a request and decodes the answer into out unless it is nil. op
+// and target describe the request in the returned *Error
+func (c *Client) do(op string, target string, req *resty.Request, method string, path string, out interface{}) error {
+	res, err := req.Execute(method, c.root+path)
+	if err := ResponseError(op, target, res, err); err != nil {
+		return err
+	}
+	if out == nil || len(res.Body()) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(res.Body(), out); err != nil {
+		return &Error{Op: op, Target: target, Err: err}
+	}
+	return nil
+}
+
+// collect reads every page of a listing, following the next links until
+// the last page
+func collect[T any](c *Client, op string, target string, path string, query url.Values) ([]T, error) {
+	if query == nil {
+		query = url.Values{}
+	}
+	var all []T
+	for start := 0; ; {
+		query.Set("start", strconv.Itoa(start))
+		query.Set("limit", strconv.Itoa(pageLimit))
+		var page model.ResultPage[T]
+		if err := c.do(op, target, c.clt.NewRequest().SetQueryParamsFromValues(query), resty.MethodGet, path, &page); err != nil {
+			return nil, err
+		}
+		all = append(all, page.Results...)
+		// Confluence may return fewer results than requested, only the
+		// next link tells whether more follow
+		if len(page.Results) == 0 || page.Links.Next == "" {
+			return all, nil
+		}
+		start += len(page.Results)
+	}
+}
+
+// expandQuery returns the expand parameter of a request
+func expandQuery(expand []string) url.Values {
+	query := url.Values{}
+	if len(expand) > 0 {
+		query.Set("expand", strings.Join(expand, ","))
+	}
+	return query
+}
+
+// contentPath returns the path of a content, or of one of its children
+// when elem is given
+func contentPath(id string, elem ...string) string {
+	path := "/content/" + url.PathEscape(id)
+	for _, e := range elem {
+		path += "/" + url.PathEscape(e)
+	}
+	return path
+}
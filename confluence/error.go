@@ -0,0 +1,83 @@
+package confluence
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/arifth/botthie/model"
+	"github.com/go-resty/resty/v2"
+)
+
+// Error is a failed Confluence request. Status and Message hold the answer
+// of Confluence, Err the transport or decoding error when there was none
+type Error struct {
+	// Op names the failed operation, e.g. "create page"
+	Op string
+	// Target names what the operation failed for, e.g. the page title
+	Target  string
+	Status  int
+	Message string
+	Err     error
+}
+
+func (e *Error) Error() string {
+	target := e.Op
+	if e.Target != "" {
+		target += " " + e.Target
+	}
+	if e.Err != nil {
+		return fmt.Sprintf("failed to %s: %v", target, e.Err)
+	}
+	return fmt.Sprintf("failed to %s: %d %s", target, e.Status, e.Message)
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// IsNotFound reports whether err is a Confluence answer of 404 Not Found
+func IsNotFound(err error) bool {
+	var e *Error
+	return errors.As(err, &e) && e.Status == http.StatusNotFound
+}
+
+// ResponseError returns the error of a Confluence request as an *Error, nil
+// when it succeeded. It serves the v2 API of Confluence Cloud as well
+func ResponseError(op string, target string, res *resty.Response, err error) error {
+	if err != nil {
+		return &Error{Op: op, Target: target, Err: err}
+	}
+	if res == nil || !res.IsError() {
+		return nil
+	}
+	return &Error{Op: op, Target: target, Status: res.StatusCode(), Message: responseMessage(res)}
+}
+
+// responseMessage reads the message of an error response: the message of
+// the v1 API, the errors of the v2 API, or the raw body
+func responseMessage(res *resty.Response) string {
+	var v1 model.Response
+	if err := json.Unmarshal(res.Body(), &v1); err == nil && v1.Message != "" {
+		return v1.Message
+	}
+	var v2 struct {
+		Errors []struct {
+			Title  string `json:"title"`
+			Detail string `json:"detail"`
+		} `json:"errors"`
+	}
+	if err := json.Unmarshal(res.Body(), &v2); err == nil && len(v2.Errors) > 0 {
+		var messages []string
+		for _, e := range v2.Errors {
+			messages = append(messages, strings.TrimSpace(e.Title+" "+e.Detail))
+		}
+		return strings.Join(messages, "; ")
+	}
+	if body := strings.TrimSpace(res.String()); body != "" {
+		return body
+	}
+	return res.Status()
+}
@@ -0,0 +1,34 @@
+package confluence
+
+import (
+	"strings"
+
+	"github.com/arifth/botthie/model"
+	"github.com/go-resty/resty/v2"
+)
+
+// Labels returns every label of a page
+func (c *Client) Labels(pageID string) ([]model.Label, error) {
+	return collect[model.Label](c, "list the labels of page", pageID, contentPath(pageID, "label"), nil)
+}
+
+// AddLabels adds global labels to a page, keeping the ones it already has,
+// and returns all its labels
+func (c *Client) AddLabels(pageID string, names ...string) ([]model.Label, error) {
+	if len(names) == 0 {
+		return nil, nil
+	}
+	var labels []model.Label
+	for _, name := range names {
+		labels = append(labels, model.Label{Prefix: "global", Name: name})
+	}
+	var page model.ResultPage[model.Label]
+	err := c.do("add labels", strings.Join(names, ", "), c.clt.NewRequest().SetBody(labels), resty.MethodPost, contentPath(pageID, "label"), &page)
+	return page.Results, err
+}
+
+// RemoveLabel removes a label from a page
+func (c *Client) RemoveLabel(pageID string, name string) error {
+	req := c.clt.NewRequest().SetQueryParam("name", name)
+	return c.do("remove label", name, req, resty.MethodDelete, contentPath(pageID, "label"), nil)
+}
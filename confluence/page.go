@@ -0,0 +1,79 @@
+package confluence
+
+import (
+	"errors"
+
+	"github.com/arifth/botthie/model"
+	"github.com/go-resty/resty/v2"
+)
+
+// GetPage returns a page with the given parts expanded, e.g. "version" or
+// "body.storage"
+func (c *Client) GetPage(id string, expand ...string) (model.Content, error) {
+	var page model.Content
+	err := c.do("read page", id, c.clt.NewRequest().SetQueryParamsFromValues(expandQuery(expand)), resty.MethodGet, contentPath(id), &page)
+	return page, err
+}
+
+// CreatePage creates a page below the ancestor of page, or at the top of
+// its space without one
+func (c *Client) CreatePage(page model.Content) (model.Content, error) {
+	if page.Type == "" {
+		page.Type = "page"
+	}
+	var created model.Content
+	err := c.do("create page", page.Title, c.clt.NewRequest().SetBody(page), resty.MethodPost, "/content", &created)
+	return created, err
+}
+
+// UpdatePage replaces page, identified by its id. Version must hold the next
+// version number of the page
+func (c *Client) UpdatePage(page model.Content) (model.Content, error) {
+	if page.Version == nil {
+		return model.Content{}, &Error{Op: "update page", Target: page.Title, Err: errors.New("missing version")}
+	}
+	if page.Type == "" {
+		page.Type = "page"
+	}
+	var updated model.Content
+	err := c.do("update page", page.Title, c.clt.NewRequest().SetBody(page), resty.MethodPut, contentPath(page.ID), &updated)
+	return updated, err
+}
+
+// DeletePage moves a page to the trash of its space
+func (c *Client) DeletePage(id string) error {
+	return c.do("remove page", id, c.clt.NewRequest(), resty.MethodDelete, contentPath(id), nil)
+}
+
+// MovePage moves a page below parentID, keeping its content
+func (c *Client) MovePage(id string, parentID string) (model.Content, error) {
+	page, err := c.GetPage(id, "version", "space", "body.storage")
+	if err != nil {
+		return model.Content{}, err
+	}
+	if page.Version == nil {
+		return model.Content{}, &Error{Op: "move page", Target: id, Err: errors.New("missing version")}
+	}
+	return c.UpdatePage(model.Content{
+		ID:        page.ID,
+		Type:      page.Type,
+		Title:     page.Title,
+		Space:     page.Space,
+		Ancestors: []model.ContentRef{{ID: parentID}},
+		Body:      page.Body,
+		Version:   &model.Version{Number: page.Version.Number + 1, MinorEdit: true},
+	})
+}
+
+// Children returns every child page of a page with the given parts expanded
+func (c *Client) Children(id string, expand ...string) ([]model.Content, error) {
+	return collect[model.Content](c, "list the children of page", id, contentPath(id, "child", "page"), expandQuery(expand))
+}
+
+// Search returns every content matching a CQL query, e.g.
+// `type = page and label = "orders"`
+func (c *Client) Search(cql string, expand ...string) ([]model.Content, error) {
+	query := expandQuery(expand)
+	query.Set("cql", cql)
+	return collect[model.Content](c, "search", cql, "/content/search", query)
+}
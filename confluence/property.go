@@ -0,0 +1,52 @@
+package confluence
+
+import (
+	"github.com/arifth/botthie/model"
+	"github.com/go-resty/resty/v2"
+)
+
+// Properties returns every content property of a page
+func (c *Client) Properties(pageID string) ([]model.ContentProperty, error) {
+	return collect[model.ContentProperty](c, "list the properties of page", pageID, contentPath(pageID, "property"), nil)
+}
+
+// Property returns the content property of a page stored under key. A
+// missing property fails with an error IsNotFound reports
+func (c *Client) Property(pageID string, key string) (model.ContentProperty, error) {
+	var prop model.ContentProperty
+	err := c.do("read property", key, c.clt.NewRequest(), resty.MethodGet, contentPath(pageID, "property", key), &prop)
+	return prop, err
+}
+
+// CreateProperty stores a new content property on a page
+func (c *Client) CreateProperty(pageID string, key string, value interface{}) (model.ContentProperty, error) {
+	var created model.ContentProperty
+	body := model.ContentProperty{Key: key, Value: value}
+	err := c.do("store property", key, c.clt.NewRequest().SetBody(body), resty.MethodPost, contentPath(pageID, "property"), &created)
+	return created, err
+}
+
+// SetProperty stores a content property on a page, creating it or adding a
+// version to the existing one
+func (c *Client) SetProperty(pageID string, key string, value interface{}) (model.ContentProperty, error) {
+	current, err := c.Property(pageID, key)
+	if IsNotFound(err) {
+		return c.CreateProperty(pageID, key, value)
+	}
+	if err != nil {
+		return model.ContentProperty{}, err
+	}
+	version := 1
+	if current.Version != nil {
+		version = current.Version.Number + 1
+	}
+	var updated model.ContentProperty
+	body := model.ContentProperty{Key: key, Value: value, Version: &model.Version{Number: version, MinorEdit: true}}
+	err = c.do("store property", key, c.clt.NewRequest().SetBody(body), resty.MethodPut, contentPath(pageID, "property", key), &updated)
+	return updated, err
+}
+
+// DeleteProperty removes a content property from a page
+func (c *Client) DeleteProperty(pageID string, key string) error {
+	return c.do("remove property", key, c.clt.NewRequest(), resty.MethodDelete, contentPath(pageID, "property", key), nil)
+}
@@ -2,12 +2,14 @@ package main
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"os"
 	"os/signal"
+	"path/filepath"
 	"strings"
+	"sync"
 	"syscall"
 
 	"github.com/arifth/botthie/model"
@@ -31,13 +33,35 @@ import (
 // Global client variable to access in event handlers
 var waClient *whatsmeow.Client
 
+// Outputs selectable with /generate [format]
+const (
+	outputConfluence = "confluence"
+	outputMarkdown   = "md"
+	outputSite       = "site"
+	outputPDF        = "pdf"
+	outputDOCX       = "docx"
+	outputXLSX       = "xlsx"
+	outputXLSXSheet  = "xlsx-sheet"
+	// the OpenAPI and Postman outputs convert Postman collections instead of
+	// rendering the imported documents
+	outputOpenAPI     = "openapi"
+	outputOpenAPIJSON = "openapi-json"
+	outputPostman     = "postman"
+)
+
+// outputFormats remembers the output chosen by each chat
+var outputFormats sync.Map
+
+// docxTemplates remembers the .docx style template uploaded by each chat
+var docxTemplates sync.Map
+
 func main() {
 
 	err := godotenv.Load()
 	if err != nil {
 		log.Fatalf("Error loading .env file: %v", err)
 	}
-	dataTempl, err := util.GetDataFromTemplate("./template/apiBook.html")
+	dataTempl, err := util.LoadTemplates("./template")
 
 	if err != nil {
 		fmt.Println(err)
@@ -167,14 +191,14 @@ func saveQRCodeImage(code string) error {
 	return nil
 }
 
-func handleEvents(evt interface{}, templ string) {
+func handleEvents(evt interface{}, templ model.Templates) {
 	switch v := evt.(type) {
 	case *events.Message:
 		handleMessage(v, templ)
 	}
 }
 
-func handleMessage(evt *events.Message, templ string) {
+func handleMessage(evt *events.Message, templ model.Templates) {
 	// Skip messages sent by bot itself
 	if evt.Info.IsFromMe {
 		return
@@ -190,7 +214,7 @@ func handleMessage(evt *events.Message, templ string) {
 		}
 
 		if strings.HasPrefix(text, "/generate") {
-			sendMessage(evt.Info.Chat, "Please send a Postman collection JSON file.")
+			handleGenerate(evt.Info.Chat, text)
 		}
 		return
 	}
@@ -201,14 +225,85 @@ func handleMessage(evt *events.Message, templ string) {
 		//instantiate new dependency per request
 		ctx := context.Background()
 		uc := usecase.NewUsecase(ctx, waClient, evt.Info.Chat)
-		// Check if it's a JSON file
-		if strings.HasSuffix(strings.ToLower(doc.GetFileName()), ".json") {
-			handlePostmanCollection(uc, evt.Info.Chat, doc, templ)
+		fileName := strings.ToLower(doc.GetFileName())
+		format := outputFormat(evt.Info.Chat)
+		switch {
+		case format == outputOpenAPI || format == outputOpenAPIJSON:
+			handleOpenAPIExport(uc, evt.Info.Chat, doc, format)
+		case format == outputPostman:
+			handlePostmanExport(uc, evt.Info.Chat, doc)
+		case format == outputDOCX && strings.HasSuffix(fileName, ".docx"):
+			handleDOCXTemplate(evt.Info.Chat, doc)
+		case strings.HasSuffix(fileName, ".zip"):
+			handleArchive(uc, evt.Info.Chat, doc, templ, format)
+		default:
+			handleDocument(uc, evt.Info.Chat, doc, templ, format)
+		}
+	}
+}
+
+// handleGenerate switches the output of the chat with /generate [format] and
+// lists the supported documents
+func handleGenerate(chatJID types.JID, text string) {
+	format := outputConfluence
+	if fields := strings.Fields(text); len(fields) > 1 {
+		format = strings.ToLower(fields[1])
+	}
+	switch format {
+	case outputConfluence:
+		outputFormats.Store(chatJID, format)
+		sendMessage(chatJID, "Documents will be published to Confluence.")
+	case outputMarkdown, "markdown":
+		outputFormats.Store(chatJID, outputMarkdown)
+		sendMessage(chatJID, "Documents will be sent back as a zip of Markdown files. Send /generate to publish to Confluence again.")
+	case outputSite, "html":
+		outputFormats.Store(chatJID, outputSite)
+		sendMessage(chatJID, "Documents will be rendered as a static HTML site. Send /generate to publish to Confluence again.")
+	case outputPDF:
+		outputFormats.Store(chatJID, outputPDF)
+		sendMessage(chatJID, "Documents will be sent back as a PDF API book. Send /generate to publish to Confluence again.")
+	case outputOpenAPI:
+		if len(strings.Fields(text)) > 2 && strings.EqualFold(strings.Fields(text)[2], "json") {
+			format = outputOpenAPIJSON
 		}
+		outputFormats.Store(chatJID, format)
+		sendMessage(chatJID, "Postman collections will be converted to OpenAPI 3.1. Send a collection, or a .zip with collections and environments, or /generate to publish to Confluence again.")
+		return
+	case outputPostman:
+		outputFormats.Store(chatJID, outputPostman)
+		sendMessage(chatJID, "Postman collections will be sent back as v2.1 collections with the generated documentation, ready to re-import into Postman. Send a collection, or a .zip with collections and environments, or /generate to publish to Confluence again.")
+		return
+	case outputXLSX, "excel":
+		mode := "one sheet per endpoint"
+		format = outputXLSX
+		if len(strings.Fields(text)) > 2 && strings.EqualFold(strings.Fields(text)[2], "single") {
+			mode = "a single sheet"
+			format = outputXLSXSheet
+		}
+		outputFormats.Store(chatJID, format)
+		sendMessage(chatJID, fmt.Sprintf("Documents will be sent back as an Excel data dictionary with %s. Send /generate to publish to Confluence again.", mode))
+	case outputDOCX, "word":
+		outputFormats.Store(chatJID, outputDOCX)
+		docxTemplates.Delete(chatJID)
+		sendMessage(chatJID, "Documents will be sent back as a Word document. Send a .docx first to use its styles, or /generate to publish to Confluence again.")
+	default:
+		sendMessage(chatJID, fmt.Sprintf("Unknown output %s, use /generate, /generate md, /generate site, /generate pdf, /generate docx, /generate xlsx [single], /generate openapi [json] or /generate postman", format))
+		return
+	}
+	sendMessage(chatJID, "Please send a Postman collection, an OpenAPI or Swagger document, a HAR capture, an Insomnia export, a GraphQL schema (.graphql SDL or introspection JSON), gRPC .proto files, an AsyncAPI document, a WSDL, or a .zip with several documents, environments and supporting files.")
+}
+
+// outputFormat returns the output chosen by the chat, Confluence by default
+func outputFormat(chatJID types.JID) string {
+	if format, ok := outputFormats.Load(chatJID); ok {
+		return format.(string)
 	}
+	return outputConfluence
 }
 
-func handlePostmanCollection(uc *usecase.Usecase, chatJID types.JID, doc *waE2E.DocumentMessage, templ string) {
+// handleDocument imports a single uploaded document of any supported format
+// and publishes it
+func handleDocument(uc *usecase.Usecase, chatJID types.JID, doc *waE2E.DocumentMessage, templ model.Templates, format string) {
 	ctx := context.Background()
 	// Download the document
 	data, err := waClient.Download(ctx, doc)
@@ -217,31 +312,103 @@ func handlePostmanCollection(uc *usecase.Usecase, chatJID types.JID, doc *waE2E.
 		return
 	}
 
-	// Parse Postman collection
-	var collection model.PostmanCollection
-	err = json.Unmarshal(data, &collection)
-	if err != nil {
-		sendMessage(chatJID, fmt.Sprintf("Failed to parse Postman collection: %v", err))
+	apiDoc, importer, err := usecase.ImportDocument(doc.GetFileName(), data, nil)
+	switch {
+	case errors.Is(err, usecase.ErrUnknownFormat):
+		sendMessage(chatJID, fmt.Sprintf("Unsupported file %s, send /generate to see the supported formats", doc.GetFileName()))
 		return
-	}
-
-	valid := util.Validate(collection)
-	if !valid {
+	case errors.Is(err, usecase.ErrInvalidCollection):
 		errorMsg := "Collection Postman tidak valid, mohon sesuaikan dengan template berikut"
-		err := usecase.SendDocumentAndImage(waClient, chatJID, ".env", errorMsg)
-		if err != nil {
-			return
+		if err := usecase.SendDocumentAndImage(waClient, chatJID, ".env", errorMsg); err != nil {
+			fmt.Printf("Error sending template: %v\n", err)
 		}
-		//sendMessage(chatJID, fmt.Sprintf("Invalid Postman collection: %v", err))
 		return
+	case err != nil:
+		sendMessage(chatJID, fmt.Sprintf("Failed to parse %s: %v", importer.Name(), err))
+		return
+	}
+
+	results := usecase.Publish(apiDoc.Name, []model.APIDocument{apiDoc}, publishers(uc, chatJID, templ, format))
+	uc.SendMessageAll(uc, usecase.PublishSummary(apiDoc.Name, results))
+}
+
+// handleDOCXTemplate keeps an uploaded .docx as the style template of the
+// chat's Word documents
+func handleDOCXTemplate(chatJID types.JID, doc *waE2E.DocumentMessage) {
+	data, err := waClient.Download(context.Background(), doc)
+	if err != nil {
+		sendMessage(chatJID, fmt.Sprintf("Failed to download file: %v", err))
+		return
+	}
+	if !usecase.IsDOCXTemplate(data) {
+		sendMessage(chatJID, fmt.Sprintf("%s is not a Word document with styles", doc.GetFileName()))
+		return
+	}
+	docxTemplates.Store(chatJID, data)
+	sendMessage(chatJID, fmt.Sprintf("Word documents will use the styles of %s", doc.GetFileName()))
+}
+
+// handleOpenAPIExport converts an uploaded Postman collection, or the
+// collections of a zip, to OpenAPI and sends the result back
+func handleOpenAPIExport(uc *usecase.Usecase, chatJID types.JID, doc *waE2E.DocumentMessage, format string) {
+	encoding := "yaml"
+	if format == outputOpenAPIJSON {
+		encoding = "json"
+	}
+	handleConversion(uc, chatJID, doc, "OpenAPI 3.1", "openapi", func(fileName string, data []byte) (map[string][]byte, error) {
+		return usecase.ConvertPostmanToOpenAPI(fileName, data, encoding)
+	})
+}
+
+// handlePostmanExport exports an uploaded Postman collection, or the
+// collections of a zip, enriched with the generated documentation. When
+// PARENT_ID is set, the edits reviewers made on the pages published below it
+// are merged in, and failing to read them fails the export
+func handlePostmanExport(uc *usecase.Usecase, chatJID types.JID, doc *waE2E.DocumentMessage) {
+	var edits usecase.ReviewedEdits
+	if parentID := os.Getenv("PARENT_ID"); parentID != "" {
+		edits = usecase.ConfluenceEdits(parentID)
 	}
+	handleConversion(uc, chatJID, doc, "enriched Postman collection", "postman", func(fileName string, data []byte) (map[string][]byte, error) {
+		return usecase.ExportPostmanCollections(fileName, data, edits)
+	})
+}
 
-	_, err = uc.PostBulkToConfluence(collection, templ, uc)
+// handleConversion converts an uploaded file with convert and sends the
+// result back, zipped and named with suffix when there are several files
+func handleConversion(uc *usecase.Usecase, chatJID types.JID, doc *waE2E.DocumentMessage, label string, suffix string, convert func(fileName string, data []byte) (map[string][]byte, error)) {
+	data, err := waClient.Download(context.Background(), doc)
 	if err != nil {
-		uc.SendMessageAll(uc, "error sending postman collection")
+		sendMessage(chatJID, fmt.Sprintf("Failed to download file: %v", err))
+		return
+	}
+	files, err := convert(doc.GetFileName(), data)
+	if err != nil {
+		sendMessage(chatJID, fmt.Sprintf("Failed to convert %s to %s: %v", doc.GetFileName(), label, err))
+		return
+	}
+	caption := fmt.Sprintf("🔁 %s of %s", label, doc.GetFileName())
+	if len(files) == 1 {
+		for name, content := range files {
+			err = uc.SendFile(name, content, caption, uc)
+		}
+	} else {
+		err = uc.SendZip(archiveName(doc.GetFileName())+"-"+suffix+".zip", files, caption, uc)
+	}
+	if err != nil {
+		uc.SendMessageAll(uc, fmt.Sprintf("error sending %s output: %v", label, err))
 	}
 }
 
+// archiveName returns the name of an uploaded file without its .zip
+// extension, whatever its case
+func archiveName(fileName string) string {
+	if ext := filepath.Ext(fileName); strings.EqualFold(ext, ".zip") {
+		return strings.TrimSuffix(fileName, ext)
+	}
+	return fileName
+}
+
 // determineType determines the data type from a value
 func sendMessage(chatJID types.JID, text string) {
 	msg := &waE2E.Message{
@@ -253,3 +420,86 @@ func sendMessage(chatJID types.JID, text string) {
 		fmt.Printf("Error sending message: %v\n", err)
 	}
 }
+
+func handleArchive(uc *usecase.Usecase, chatJID types.JID, doc *waE2E.DocumentMessage, templ model.Templates, format string) {
+	ctx := context.Background()
+	// Download the document
+	data, err := waClient.Download(ctx, doc)
+	if err != nil {
+		sendMessage(chatJID, fmt.Sprintf("Failed to download file: %v", err))
+		return
+	}
+
+	// the documents of the archive are published together as one job, the
+	// outcome of each target is then reported per file
+	var docs []model.APIDocument
+	var files []string
+	collect := func(file string, apiDoc model.APIDocument) (int, error) {
+		docs = append(docs, apiDoc)
+		files = append(files, file)
+		return usecase.PageCount(apiDoc), nil
+	}
+	results, err := usecase.ProcessArchive(data, collect)
+	if err != nil {
+		sendMessage(chatJID, fmt.Sprintf("Failed to read zip archive: %v", err))
+		return
+	}
+
+	var published []model.PublishResult
+	name := archiveName(doc.GetFileName())
+	if len(docs) > 0 {
+		published = usecase.Publish(name, docs, publishers(uc, chatJID, templ, format))
+		results = usecase.ArchiveResults(results, files, published)
+	}
+	uc.SendMessageAll(uc, usecase.ArchiveSummary(doc.GetFileName(), results))
+	if len(published) > 0 {
+		uc.SendMessageAll(uc, usecase.PublishSummary(name, published))
+	}
+}
+
+// publishers returns the targets of a job: the output chosen in the chat
+// followed by the ones configured in PUBLISH_TARGETS
+func publishers(uc *usecase.Usecase, chatJID types.JID, templ model.Templates, format string) []usecase.Publisher {
+	var res []usecase.Publisher
+	if format == outputConfluence {
+		res = append(res, usecase.ConfluencePublisher{Templates: templ, UC: uc})
+	} else {
+		res = append(res, usecase.ReplyPublisher{
+			Format: format,
+			Send: func(name string, docs []model.APIDocument) error {
+				return deliverDocuments(uc, chatJID, templ, format, name, docs)
+			},
+		})
+	}
+	for _, p := range usecase.ConfiguredPublishers(templ, uc) {
+		// the chat already publishes to Confluence
+		if _, ok := p.(usecase.ConfluencePublisher); ok && format == outputConfluence {
+			continue
+		}
+		res = append(res, p)
+	}
+	return res
+}
+
+// deliverDocuments renders the documents in a non-Confluence output and sends
+// the result back to the chat
+func deliverDocuments(uc *usecase.Usecase, chatJID types.JID, templ model.Templates, format string, name string, docs []model.APIDocument) error {
+	switch format {
+	case outputMarkdown:
+		return uc.SendMarkdown(name, docs, uc)
+	case outputSite:
+		return uc.DeliverSite(name, docs, templ, uc)
+	case outputPDF:
+		return uc.SendPDF(name, docs, uc)
+	case outputDOCX:
+		var styleTemplate []byte
+		if data, ok := docxTemplates.Load(chatJID); ok {
+			styleTemplate = data.([]byte)
+		}
+		return uc.SendDOCX(name, docs, styleTemplate, uc)
+	case outputXLSX, outputXLSXSheet:
+		return uc.SendXLSX(name, docs, format == outputXLSXSheet, uc)
+	default:
+		return fmt.Errorf("unknown output %s", format)
+	}
+}
@@ -0,0 +1,120 @@
+package model
+
+// APIDocument is the format-neutral description every importer produces and
+// every renderer consumes
+type APIDocument struct {
+	Name        string
+	Format      string
+	Description string
+	Version     string
+	Services    []APIService
+	Auth        []APIAuth
+	Types       []APIType
+	// ID identifies the source across uploads, e.g. the _postman_id of a
+	// collection
+	ID string
+	// SourceName and Source hold the uploaded file the document was imported
+	// from
+	SourceName string
+	Source     []byte
+}
+
+// APIService groups endpoints: a Postman folder, an OpenAPI tag, a gRPC
+// service, a SOAP port or an event channel
+type APIService struct {
+	Name        string
+	Description string
+	BaseURL     string
+	Endpoints   []APIEndpoint
+}
+
+// APIEndpoint is one documented operation: an HTTP request, a GraphQL root
+// field, a gRPC method, an event channel operation or a SOAP operation
+type APIEndpoint struct {
+	Name        string
+	Kind        string
+	Method      string
+	URL         string
+	Description string
+	Deprecated  string
+	Attributes  []APIAttribute
+	Auth        []APIAuth
+	Headers     []APIParameter
+	Parameters  []APIParameter
+	Body        *APIBody
+	Responses   []APIResponse
+	Examples    []APIExample
+	Types       []APIType
+}
+
+// APIAttribute is a protocol specific key/value detail, e.g. the SOAPAction
+// or the gRPC streaming mode
+type APIAttribute struct {
+	Key   string
+	Value string
+}
+
+// APIParameter is a header, path, query, argument or channel parameter
+type APIParameter struct {
+	Name        string
+	In          string
+	Type        string
+	Required    bool
+	Default     string
+	Example     string
+	Description string
+}
+
+// APIBody is a request, response or message body
+type APIBody struct {
+	MediaType string
+	Mode      string
+	Raw       string
+	Fields    []APIField
+}
+
+// APIField is one (possibly dotted) field of a body or type
+type APIField struct {
+	Name        string
+	Type        string
+	Required    bool
+	Default     string
+	Description string
+}
+
+// APIResponse is one documented response of an endpoint
+type APIResponse struct {
+	Status      string
+	Description string
+	Headers     []APIParameter
+	Body        *APIBody
+}
+
+// APIAuth is an authentication scheme, either offered by the document or
+// required by an endpoint
+type APIAuth struct {
+	Name        string
+	Type        string
+	In          string
+	Key         string
+	Description string
+	Scopes      []string
+}
+
+// APIExample is a recorded or sample exchange, e.g. a Postman saved response
+// or a SOAP envelope
+type APIExample struct {
+	Name     string
+	Status   string
+	Request  string
+	Response string
+}
+
+// APIType is a named model: an object type, a message or an enum
+type APIType struct {
+	Name        string
+	Kind        string
+	Description string
+	Fields      []APIField
+	Values      []APIField
+}
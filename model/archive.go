@@ -0,0 +1,9 @@
+package model
+
+// FileResult is the processing outcome of one file of an uploaded archive
+type FileResult struct {
+	File  string
+	Kind  string
+	Pages int
+	Err   error
+}
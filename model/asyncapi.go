@@ -0,0 +1,44 @@
+package model
+
+// AsyncAPIDoc is the documentation view of an AsyncAPI 2.x or 3.x document
+type AsyncAPIDoc struct {
+	Version     string
+	Title       string
+	APIVersion  string
+	Description string
+	Operations  []AsyncOperation
+}
+
+// AsyncOperation is one publish/subscribe (2.x) or send/receive (3.x)
+// operation on a channel
+type AsyncOperation struct {
+	Channel         string
+	Address         string
+	Action          string
+	OperationID     string
+	Summary         string
+	Description     string
+	Parameters      []BodyField
+	Messages        []AsyncMessage
+	Bindings        []AsyncBinding
+	ChannelBindings []AsyncBinding
+}
+
+// AsyncMessage is a message that can flow through an operation
+type AsyncMessage struct {
+	Name        string
+	Title       string
+	Summary     string
+	Description string
+	ContentType string
+	Headers     []BodyField
+	Payload     []BodyField
+	Bindings    []AsyncBinding
+}
+
+// AsyncBinding is one flattened protocol binding value, e.g. kafka / topic
+type AsyncBinding struct {
+	Protocol string
+	Key      string
+	Value    string
+}
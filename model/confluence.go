@@ -1,31 +1,151 @@
 package model
 
-// The top-level struct matching the main JSON object
-type ConfluencePage struct {
-	Type      string      `json:"type"`
-	Title     string      `json:"title"`
-	Ancestors []Ancestor  `json:"ancestors"`
-	Space     Space       `json:"space"`
-	Body      BodyWrapper `json:"body"`
+import "strings"
+
+// Content is a page, or an attachment, of the Confluence REST API, both as
+// sent and as returned. The optional parts are only returned when expanded
+type Content struct {
+	ID         string                `json:"id,omitempty"`
+	Type       string                `json:"type"`
+	Status     string                `json:"status,omitempty"`
+	Title      string                `json:"title"`
+	Space      *ContentSpace         `json:"space,omitempty"`
+	Ancestors  []ContentRef          `json:"ancestors,omitempty"`
+	Body       *ContentBody          `json:"body,omitempty"`
+	Version    *Version              `json:"version,omitempty"`
+	Metadata   *ContentMetadata      `json:"metadata,omitempty"`
+	Extensions *AttachmentExtensions `json:"extensions,omitempty"`
+	Links      *ContentLinks         `json:"_links,omitempty"`
+}
+
+// WebURL returns the address of the content in the web UI, empty when the
+// response carries no links. base is the address of the site, used when the
+// response has no base link as Cloud v2 responses often do
+func (c Content) WebURL(base string) string {
+	if c.Links == nil || c.Links.WebUI == "" {
+		return ""
+	}
+	if c.Links.Base != "" {
+		base = c.Links.Base
+	}
+	return strings.TrimSuffix(base, "/") + c.Links.WebUI
 }
 
-// Struct for the "ancestors" array elements
-type Ancestor struct {
+// ContentRef references a page by id, e.g. as the ancestor of a page
+type ContentRef struct {
 	ID string `json:"id"`
 }
 
-// Struct for the "space" object
-type Space struct {
-	Key string `json:"key"`
+// ContentSpace is the space of a page
+type ContentSpace struct {
+	ID   int    `json:"id,omitempty"`
+	Key  string `json:"key"`
+	Name string `json:"name,omitempty"`
+	Type string `json:"type,omitempty"`
 }
 
-// Struct for the "body" object
-type BodyWrapper struct {
-	Storage Storage `json:"storage"`
+// ContentBody holds the representations of a page
+type ContentBody struct {
+	Storage *ContentStorage `json:"storage,omitempty"`
 }
 
-// Struct for the "storage" object
-type Storage struct {
+// ContentStorage is a representation of a page, e.g. storage format XHTML
+type ContentStorage struct {
 	Value          string `json:"value"`
 	Representation string `json:"representation"`
 }
+
+// ContentMetadata holds the expanded labels and properties of a page, and
+// the media type of an attachment
+type ContentMetadata struct {
+	Labels     *ResultPage[Label]         `json:"labels,omitempty"`
+	Properties map[string]ContentProperty `json:"properties,omitempty"`
+	MediaType  string                     `json:"mediaType,omitempty"`
+	Comment    string                     `json:"comment,omitempty"`
+}
+
+// AttachmentExtensions describes the file of an attachment
+type AttachmentExtensions struct {
+	MediaType string `json:"mediaType"`
+	FileSize  int64  `json:"fileSize"`
+	Comment   string `json:"comment,omitempty"`
+}
+
+// ContentLinks are the addresses of a content, relative to Base
+type ContentLinks struct {
+	Base     string `json:"base,omitempty"`
+	Context  string `json:"context,omitempty"`
+	Self     string `json:"self,omitempty"`
+	WebUI    string `json:"webui,omitempty"`
+	Download string `json:"download,omitempty"`
+	Next     string `json:"next,omitempty"`
+}
+
+// ResultPage is one page of a paginated listing: children, search results,
+// attachments, labels or properties
+type ResultPage[T any] struct {
+	Results []T          `json:"results"`
+	Start   int          `json:"start"`
+	Limit   int          `json:"limit"`
+	Size    int          `json:"size"`
+	Links   ContentLinks `json:"_links"`
+}
+
+// Version is the version of a page or property, incremented by every update
+type Version struct {
+	Number    int    `json:"number"`
+	MinorEdit bool   `json:"minorEdit,omitempty"`
+	Message   string `json:"message,omitempty"`
+}
+
+// ContentProperty is a JSON value stored on a page under a key
+type ContentProperty struct {
+	ID      string      `json:"id,omitempty"`
+	Key     string      `json:"key"`
+	Value   interface{} `json:"value"`
+	Version *Version    `json:"version,omitempty"`
+}
+
+// Label is a label of a page; global labels are visible to every user
+type Label struct {
+	ID     string `json:"id,omitempty"`
+	Prefix string `json:"prefix"`
+	Name   string `json:"name"`
+}
+
+// DocPage is a rendered child page waiting to be published. ADF holds the
+// page as an Atlassian Document Format JSON document for Confluence Cloud.
+// Key identifies the page within its document across publications and
+// Attachments holds the files attached to the page by name. Group, Method,
+// URL and Description describe the page in the index of its parent. Labels
+// are added to the published page
+type DocPage struct {
+	Key         string
+	Title       string
+	HTML        string
+	ADF         string
+	Attachments map[string][]byte
+	Group       string
+	Method      string
+	URL         string
+	Description string
+	Labels      []string
+}
+
+// CloudPage is the body of a Confluence Cloud v2 page
+type CloudPage struct {
+	ID       string    `json:"id,omitempty"`
+	SpaceID  string    `json:"spaceId"`
+	Status   string    `json:"status"`
+	Title    string    `json:"title"`
+	ParentID string    `json:"parentId,omitempty"`
+	Body     CloudBody `json:"body"`
+	Version  *Version  `json:"version,omitempty"`
+}
+
+// CloudBody is the content of a v2 page in the storage or atlas_doc_format
+// representation
+type CloudBody struct {
+	Representation string `json:"representation"`
+	Value          string `json:"value"`
+}
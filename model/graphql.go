@@ -0,0 +1,43 @@
+package model
+
+// GraphQLSchema is the documentation view of a GraphQL schema, built either
+// from an SDL file or from an introspection query result
+type GraphQLSchema struct {
+	Name       string
+	Operations []GraphQLOperation
+	Types      []GraphQLType
+}
+
+// GraphQLOperation is a single root field of the query, mutation or
+// subscription type
+type GraphQLOperation struct {
+	Kind         string
+	Name         string
+	Description  string
+	Deprecated   string
+	Arguments    []GraphQLField
+	ReturnType   string
+	ReturnFields []GraphQLField
+}
+
+// GraphQLField is one row of an argument, field or enum value table
+type GraphQLField struct {
+	Number       int
+	Name         string
+	Type         string
+	Mandatory    string
+	DefaultValue string
+	Description  string
+	Deprecated   string
+}
+
+// GraphQLType is a named type referenced by the schema operations
+type GraphQLType struct {
+	Kind          string
+	Name          string
+	Description   string
+	Interfaces    []string
+	PossibleTypes []string
+	Fields        []GraphQLField
+	EnumValues    []GraphQLField
+}
@@ -0,0 +1,62 @@
+package model
+
+// ProtoAPI is the documentation view of a set of .proto files
+type ProtoAPI struct {
+	Name     string
+	Services []ProtoService
+}
+
+// ProtoService is a gRPC service declared in a .proto file
+type ProtoService struct {
+	Name        string
+	Package     string
+	Description string
+	RPCs        []ProtoRPC
+}
+
+// ProtoRPC is a single RPC along with every other message and enum its
+// request and response reference
+type ProtoRPC struct {
+	Service      string
+	Name         string
+	Description  string
+	Streaming    string
+	RequestType  string
+	ResponseType string
+	Request      ProtoMessage
+	Response     ProtoMessage
+	Messages     []ProtoMessage
+	Enums        []ProtoEnum
+}
+
+// ProtoMessage is a message rendered as a field table
+type ProtoMessage struct {
+	Name        string
+	Description string
+	Fields      []ProtoField
+}
+
+// ProtoField is one row of a message field table
+type ProtoField struct {
+	Number      int
+	Name        string
+	Type        string
+	Label       string
+	Tag         int
+	Description string
+}
+
+// ProtoEnum is an enum rendered as a value table
+type ProtoEnum struct {
+	Name        string
+	Description string
+	Values      []ProtoEnumValue
+}
+
+// ProtoEnumValue is one row of an enum value table
+type ProtoEnumValue struct {
+	Number      int
+	Name        string
+	Value       int
+	Description string
+}
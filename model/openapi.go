@@ -0,0 +1,133 @@
+package model
+
+// OpenAPISpec is an OpenAPI 3.1 document written by the exporters. The field
+// order is the order of the keys in the generated YAML and JSON
+type OpenAPISpec struct {
+	OpenAPI    string                      `json:"openapi" yaml:"openapi"`
+	Info       OpenAPIInfo                 `json:"info" yaml:"info"`
+	Servers    []OpenAPIServer             `json:"servers,omitempty" yaml:"servers,omitempty"`
+	Security   []map[string][]string       `json:"security,omitempty" yaml:"security,omitempty"`
+	Tags       []OpenAPITag                `json:"tags,omitempty" yaml:"tags,omitempty"`
+	Paths      map[string]*OpenAPIPathItem `json:"paths" yaml:"paths"`
+	Components *OpenAPIComponents          `json:"components,omitempty" yaml:"components,omitempty"`
+}
+
+// OpenAPITag groups operations, e.g. the requests of a Postman folder
+type OpenAPITag struct {
+	Name        string `json:"name" yaml:"name"`
+	Description string `json:"description,omitempty" yaml:"description,omitempty"`
+}
+
+type OpenAPIInfo struct {
+	Title       string `json:"title" yaml:"title"`
+	Description string `json:"description,omitempty" yaml:"description,omitempty"`
+	Version     string `json:"version" yaml:"version"`
+}
+
+type OpenAPIServer struct {
+	URL         string                           `json:"url" yaml:"url"`
+	Description string                           `json:"description,omitempty" yaml:"description,omitempty"`
+	Variables   map[string]OpenAPIServerVariable `json:"variables,omitempty" yaml:"variables,omitempty"`
+}
+
+type OpenAPIServerVariable struct {
+	Default     string `json:"default" yaml:"default"`
+	Description string `json:"description,omitempty" yaml:"description,omitempty"`
+}
+
+type OpenAPIPathItem struct {
+	Get     *OpenAPIOperation `json:"get,omitempty" yaml:"get,omitempty"`
+	Put     *OpenAPIOperation `json:"put,omitempty" yaml:"put,omitempty"`
+	Post    *OpenAPIOperation `json:"post,omitempty" yaml:"post,omitempty"`
+	Delete  *OpenAPIOperation `json:"delete,omitempty" yaml:"delete,omitempty"`
+	Options *OpenAPIOperation `json:"options,omitempty" yaml:"options,omitempty"`
+	Head    *OpenAPIOperation `json:"head,omitempty" yaml:"head,omitempty"`
+	Patch   *OpenAPIOperation `json:"patch,omitempty" yaml:"patch,omitempty"`
+	Trace   *OpenAPIOperation `json:"trace,omitempty" yaml:"trace,omitempty"`
+}
+
+type OpenAPIOperation struct {
+	Summary     string                      `json:"summary,omitempty" yaml:"summary,omitempty"`
+	Description string                      `json:"description,omitempty" yaml:"description,omitempty"`
+	OperationID string                      `json:"operationId,omitempty" yaml:"operationId,omitempty"`
+	Tags        []string                    `json:"tags,omitempty" yaml:"tags,omitempty"`
+	Servers     []OpenAPIServer             `json:"servers,omitempty" yaml:"servers,omitempty"`
+	Parameters  []OpenAPIParameter          `json:"parameters,omitempty" yaml:"parameters,omitempty"`
+	RequestBody *OpenAPIRequestBody         `json:"requestBody,omitempty" yaml:"requestBody,omitempty"`
+	Responses   map[string]*OpenAPIResponse `json:"responses" yaml:"responses"`
+	// Security is a pointer so an explicit empty list can switch off the
+	// document security of a request without auth
+	Security *[]map[string][]string `json:"security,omitempty" yaml:"security,omitempty"`
+}
+
+type OpenAPIParameter struct {
+	Name        string         `json:"name" yaml:"name"`
+	In          string         `json:"in" yaml:"in"`
+	Description string         `json:"description,omitempty" yaml:"description,omitempty"`
+	Required    bool           `json:"required,omitempty" yaml:"required,omitempty"`
+	Schema      *OpenAPISchema `json:"schema,omitempty" yaml:"schema,omitempty"`
+	Example     interface{}    `json:"example,omitempty" yaml:"example,omitempty"`
+}
+
+type OpenAPIRequestBody struct {
+	Description string                       `json:"description,omitempty" yaml:"description,omitempty"`
+	Content     map[string]*OpenAPIMediaType `json:"content" yaml:"content"`
+}
+
+type OpenAPIResponse struct {
+	Description string                       `json:"description" yaml:"description"`
+	Headers     map[string]*OpenAPIHeader    `json:"headers,omitempty" yaml:"headers,omitempty"`
+	Content     map[string]*OpenAPIMediaType `json:"content,omitempty" yaml:"content,omitempty"`
+}
+
+type OpenAPIHeader struct {
+	Schema  *OpenAPISchema `json:"schema,omitempty" yaml:"schema,omitempty"`
+	Example interface{}    `json:"example,omitempty" yaml:"example,omitempty"`
+}
+
+type OpenAPIMediaType struct {
+	Schema   *OpenAPISchema            `json:"schema,omitempty" yaml:"schema,omitempty"`
+	Example  interface{}               `json:"example,omitempty" yaml:"example,omitempty"`
+	Examples map[string]OpenAPIExample `json:"examples,omitempty" yaml:"examples,omitempty"`
+}
+
+type OpenAPIExample struct {
+	Summary string      `json:"summary,omitempty" yaml:"summary,omitempty"`
+	Value   interface{} `json:"value" yaml:"value"`
+}
+
+// OpenAPISchema is the subset of JSON Schema inferred from sample values
+type OpenAPISchema struct {
+	Type        string                    `json:"type,omitempty" yaml:"type,omitempty"`
+	Format      string                    `json:"format,omitempty" yaml:"format,omitempty"`
+	Description string                    `json:"description,omitempty" yaml:"description,omitempty"`
+	Properties  map[string]*OpenAPISchema `json:"properties,omitempty" yaml:"properties,omitempty"`
+	Items       *OpenAPISchema            `json:"items,omitempty" yaml:"items,omitempty"`
+	Example     interface{}               `json:"example,omitempty" yaml:"example,omitempty"`
+}
+
+type OpenAPIComponents struct {
+	SecuritySchemes map[string]*OpenAPISecurityScheme `json:"securitySchemes,omitempty" yaml:"securitySchemes,omitempty"`
+}
+
+type OpenAPISecurityScheme struct {
+	Type        string             `json:"type" yaml:"type"`
+	Description string             `json:"description,omitempty" yaml:"description,omitempty"`
+	Scheme      string             `json:"scheme,omitempty" yaml:"scheme,omitempty"`
+	Name        string             `json:"name,omitempty" yaml:"name,omitempty"`
+	In          string             `json:"in,omitempty" yaml:"in,omitempty"`
+	Flows       *OpenAPIOAuthFlows `json:"flows,omitempty" yaml:"flows,omitempty"`
+}
+
+type OpenAPIOAuthFlows struct {
+	AuthorizationCode *OpenAPIOAuthFlow `json:"authorizationCode,omitempty" yaml:"authorizationCode,omitempty"`
+	ClientCredentials *OpenAPIOAuthFlow `json:"clientCredentials,omitempty" yaml:"clientCredentials,omitempty"`
+	Password          *OpenAPIOAuthFlow `json:"password,omitempty" yaml:"password,omitempty"`
+	Implicit          *OpenAPIOAuthFlow `json:"implicit,omitempty" yaml:"implicit,omitempty"`
+}
+
+type OpenAPIOAuthFlow struct {
+	AuthorizationURL string            `json:"authorizationUrl,omitempty" yaml:"authorizationUrl,omitempty"`
+	TokenURL         string            `json:"tokenUrl,omitempty" yaml:"tokenUrl,omitempty"`
+	Scopes           map[string]string `json:"scopes" yaml:"scopes"`
+}
@@ -3,22 +3,59 @@ package model
 // PostmanCollection represents the structure of a Postman collection
 type PostmanCollection struct {
 	Info struct {
-		Name   string `json:"name"`
-		Schema string `json:"schema"`
+		PostmanID   string      `json:"_postman_id,omitempty"`
+		Name        string      `json:"name"`
+		Schema      string      `json:"schema"`
+		Description interface{} `json:"description,omitempty"`
 	} `json:"info"`
 	Item []PostmanItem `json:"item"`
+	Auth *PostmanAuth  `json:"auth,omitempty"`
 }
 
+// PostmanItem is a request, or a folder when Item is set. The description
+// and auth of a folder apply to the requests inside it
 type PostmanItem struct {
-	Name    string         `json:"name"`
-	Request PostmanRequest `json:"request"`
+	Name        string            `json:"name"`
+	Request     PostmanRequest    `json:"request"`
+	Response    []PostmanResponse `json:"response,omitempty"`
+	Item        []PostmanItem     `json:"item,omitempty"`
+	Description interface{}       `json:"description,omitempty"`
+	Auth        *PostmanAuth      `json:"auth,omitempty"`
 }
 
 type PostmanRequest struct {
-	Method string          `json:"method"`
-	Header []PostmanHeader `json:"header"`
-	Body   *PostmanBody    `json:"body,omitempty"`
-	URL    interface{}     `json:"url"`
+	Method      string          `json:"method"`
+	Header      []PostmanHeader `json:"header"`
+	Body        *PostmanBody    `json:"body,omitempty"`
+	URL         interface{}     `json:"url"`
+	Auth        *PostmanAuth    `json:"auth,omitempty"`
+	Description interface{}     `json:"description,omitempty"`
+}
+
+// PostmanAuth is the auth of a collection or request; only the attributes of
+// the selected type are set
+type PostmanAuth struct {
+	Type   string             `json:"type"`
+	Bearer []PostmanAuthParam `json:"bearer,omitempty"`
+	Basic  []PostmanAuthParam `json:"basic,omitempty"`
+	APIKey []PostmanAuthParam `json:"apikey,omitempty"`
+	OAuth2 []PostmanAuthParam `json:"oauth2,omitempty"`
+}
+
+type PostmanAuthParam struct {
+	Key   string      `json:"key"`
+	Value interface{} `json:"value"`
+	Type  string      `json:"type,omitempty"`
+}
+
+// PostmanResponse is a saved example response of a request
+type PostmanResponse struct {
+	Name            string          `json:"name"`
+	OriginalRequest *PostmanRequest `json:"originalRequest,omitempty"`
+	Status          string          `json:"status"`
+	Code            int             `json:"code"`
+	Header          []PostmanHeader `json:"header,omitempty"`
+	Body            string          `json:"body"`
 }
 
 type PostmanHeader struct {
@@ -45,3 +82,16 @@ type PostmanURL struct {
 	Host []string `json:"host,omitempty"`
 	Path []string `json:"path,omitempty"`
 }
+
+// PostmanEnvironment represents an exported Postman environment
+type PostmanEnvironment struct {
+	Name   string                    `json:"name"`
+	Values []PostmanEnvironmentValue `json:"values"`
+	Scope  string                    `json:"_postman_variable_scope"`
+}
+
+type PostmanEnvironmentValue struct {
+	Key     string `json:"key"`
+	Value   string `json:"value"`
+	Enabled *bool  `json:"enabled,omitempty"`
+}
@@ -0,0 +1,19 @@
+package model
+
+// PublishResult is the outcome of publishing a generation job to one target
+type PublishResult struct {
+	Target string
+	// Pages counts the published pages or rendered files
+	Pages int
+	// Location tells users where to find the output, e.g. a directory
+	Location string
+	// Links lists the published pages, e.g. in Confluence
+	Links []PageLink
+	Err   error
+}
+
+// PageLink is the address of one published page
+type PageLink struct {
+	Title string
+	URL   string
+}
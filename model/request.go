@@ -9,46 +9,87 @@ type APIRequest struct {
 type TemplateData struct {
 	CollectionName string
 	Requests       RequestData
+	// Properties fill the page properties macro of Confluence pages
+	Properties []APIAttribute
 }
 
+// RequestData is the template view of one endpoint, derived from APIEndpoint
 type RequestData struct {
-	Name       string
-	Method     string
-	URL        string
-	Headers    []PostmanHeader
-	Body       string
-	BodyFields []BodyField
-	BodyMode   string
+	Service     string
+	Name        string
+	Kind        string
+	Method      string
+	URL         string
+	Description string
+	Deprecated  string
+	Attributes  []APIAttribute
+	Auth        []AuthData
+	Headers     []HeaderField
+	Parameters  []ParameterField
+	Body        string
+	BodyFields  []BodyField
+	BodyMode    string
+	Responses   []ResponseData
+	Examples    []APIExample
+	Types       []TypeData
 }
 
-type BodyField struct {
-	Field       string
+// HeaderField is one row of the request header table
+type HeaderField struct {
+	Key   string
+	Value string
+}
+
+// AuthData is one row of the authentication table
+type AuthData struct {
+	Name        string
 	Type        string
-	Mandatory   string
+	Location    string
+	Scopes      string
 	Description string
+}
+
+// ParameterField is one row of a parameter table
+type ParameterField struct {
 	Number      int
+	Name        string
+	In          string
+	Type        string
+	Mandatory   string
+	Default     string
+	Description string
 }
 
-type Links struct {
-	Webui string `json:"webui"`
-	Self  string `json:"self"`
+// ResponseData holds one documented response of a request
+type ResponseData struct {
+	Status      string
+	Description string
+	Headers     []ParameterField
+	Body        string
+	BodyFields  []BodyField
 }
 
-type Spaces struct {
-	ID    int    `json:"id"`
-	Key   string `json:"key"`
-	Name  string `json:"name"`
-	Type  string `json:"type"`
-	Links Links  `json:"_links"`
+// TypeData holds a type rendered as field and value tables
+type TypeData struct {
+	Name        string
+	Kind        string
+	Description string
+	Fields      []BodyField
+	Values      []BodyField
 }
 
-type LinksS struct {
-	Links
+// TypeTemplateData holds data for type reference page rendering
+type TypeTemplateData struct {
+	CollectionName string
+	Type           TypeData
 }
 
-type ConfluenceResponse struct {
-	Space Spaces `json:"space"`
-	Links LinksS `json: "_links`
+type BodyField struct {
+	Field       string
+	Type        string
+	Mandatory   string
+	Description string
+	Number      int
 }
 
 type Response struct {
@@ -0,0 +1,30 @@
+package model
+
+// SitePageData holds data for one page of the static documentation site
+type SitePageData struct {
+	SiteName    string
+	Title       string
+	Description string
+	Facts       string
+	// Root is the relative path from the page back to the site root
+	Root    string
+	Current string
+	Content string
+	Nav     []SiteNavGroup
+}
+
+// SiteNavGroup is one service of the site navigation
+type SiteNavGroup struct {
+	Name        string
+	Description string
+	Links       []SiteLink
+}
+
+// SiteLink is one page of the site navigation
+type SiteLink struct {
+	Title   string
+	Method  string
+	Href    string
+	Search  string
+	Summary string
+}
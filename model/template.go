@@ -0,0 +1,9 @@
+package model
+
+// Templates holds the raw page templates loaded from the template directory
+type Templates struct {
+	APIBook    string
+	Type       string
+	Site       string
+	Confluence string
+}
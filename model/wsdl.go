@@ -0,0 +1,28 @@
+package model
+
+// WSDLDoc is the documentation view of a WSDL 1.1 document and its XSD types
+type WSDLDoc struct {
+	Name            string
+	TargetNamespace string
+	Operations      []WSDLOperation
+}
+
+// WSDLOperation is one operation of a SOAP binding
+type WSDLOperation struct {
+	Service        string
+	Port           string
+	Binding        string
+	Name           string
+	Documentation  string
+	Address        string
+	SOAPAction     string
+	Style          string
+	SOAPVersion    string
+	InputElement   string
+	OutputElement  string
+	Input          []BodyField
+	Output         []BodyField
+	Faults         []string
+	SampleRequest  string
+	SampleResponse string
+}
@@ -0,0 +1,244 @@
+package usecase
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/arifth/botthie/model"
+)
+
+// ConvertToADF renders the page of one endpoint as an Atlassian Document
+// Format document with the sections of the storage format page
+func (Usecase) ConvertToADF(doc model.APIDocument, service model.APIService, endpoint model.APIEndpoint) model.ADFNode {
+	req := requestData(service, endpoint)
+	content := []model.ADFNode{adfHeading(1, doc.Name), adfHeading(3, req.Name)}
+	if req.Service != "" {
+		content = append(content, adfParagraph(adfText("Service: ", "strong"), adfText(req.Service)))
+	}
+	content = append(content, adfCodeBlock(strings.TrimSpace(req.Method+" "+req.URL), ""))
+	if req.Description != "" {
+		content = append(content, adfParagraph(adfText(req.Description)))
+	}
+	if req.Deprecated != "" {
+		content = append(content, adfParagraph(adfText("Deprecated: ", "strong"), adfText(req.Deprecated)))
+	}
+
+	if len(req.Attributes) > 0 {
+		var rows [][]string
+		for _, a := range req.Attributes {
+			rows = append(rows, []string{a.Key, a.Value})
+		}
+		content = append(content, adfTable([]string{"Key", "Value"}, rows))
+	}
+	if len(req.Auth) > 0 {
+		content = append(content, adfHeading(2, "Authentication"), adfAuthTable(req.Auth))
+	}
+	if len(req.Headers) > 0 {
+		var rows [][]string
+		for _, h := range req.Headers {
+			rows = append(rows, []string{h.Key, h.Value})
+		}
+		content = append(content, adfHeading(2, "Headers"), adfTable([]string{"Key", "Value"}, rows))
+	}
+	if len(req.Parameters) > 0 {
+		var rows [][]string
+		for _, p := range req.Parameters {
+			rows = append(rows, []string{fmt.Sprint(p.Number), p.Name, p.In, p.Type, p.Mandatory, p.Default, p.Description})
+		}
+		content = append(content, adfHeading(2, "Parameters"),
+			adfTable([]string{"No.", "Name", "In", "Type", "Mandatory", "Default", "Description"}, rows))
+	}
+	if len(req.BodyFields) > 0 || req.Body != "" {
+		content = append(content, adfHeading(2, "Request Body"))
+		content = append(content, adfFieldTable(req.BodyFields)...)
+		content = append(content, adfBody(req.Body)...)
+	}
+	for _, r := range req.Responses {
+		content = append(content, adfHeading(2, "Response "+r.Status))
+		if r.Description != "" {
+			content = append(content, adfParagraph(adfText(r.Description)))
+		}
+		if len(r.Headers) > 0 {
+			var rows [][]string
+			for _, h := range r.Headers {
+				rows = append(rows, []string{fmt.Sprint(h.Number), h.Name, h.Type, h.Description})
+			}
+			content = append(content, adfTable([]string{"No.", "Header", "Type", "Description"}, rows))
+		}
+		content = append(content, adfFieldTable(r.BodyFields)...)
+		content = append(content, adfBody(r.Body)...)
+	}
+	if len(req.Examples) > 0 {
+		content = append(content, adfHeading(2, "Examples"))
+		for _, e := range req.Examples {
+			content = append(content, adfHeading(3, firstNonEmpty(e.Name, "Example")))
+			if e.Request != "" {
+				content = append(content, adfParagraph(adfText("Request", "strong")))
+				content = append(content, adfBody(e.Request)...)
+			}
+			if e.Response != "" {
+				content = append(content, adfParagraph(adfText(strings.TrimSpace("Response "+e.Status), "strong")))
+				content = append(content, adfBody(e.Response)...)
+			}
+		}
+	}
+	for _, t := range req.Types {
+		content = append(content, adfHeading(2, fmt.Sprintf("%s %s", t.Kind, t.Name)))
+		content = append(content, adfTypeBody(t)...)
+	}
+	return adfDoc(content)
+}
+
+// ConvertTypeToADF renders a type reference page as an Atlassian Document
+// Format document
+func (Usecase) ConvertTypeToADF(doc model.APIDocument, t model.APIType) model.ADFNode {
+	rows := typeRows([]model.APIType{t})
+	content := []model.ADFNode{
+		adfHeading(1, doc.Name),
+		adfHeading(3, t.Name),
+		adfParagraph(adfText("Kind: ", "strong"), adfText(t.Kind)),
+	}
+	return adfDoc(append(content, adfTypeBody(rows[0])...))
+}
+
+// ADFString encodes a document as the value of an atlas_doc_format body
+func ADFString(doc model.ADFNode) string {
+	data, err := json.Marshal(doc)
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
+
+func adfTypeBody(t model.TypeData) []model.ADFNode {
+	var content []model.ADFNode
+	if t.Description != "" {
+		content = append(content, adfParagraph(adfText(t.Description)))
+	}
+	if len(t.Fields) > 0 {
+		content = append(content, adfHeading(2, "Fields"))
+		content = append(content, adfFieldTable(t.Fields)...)
+	}
+	if len(t.Values) > 0 {
+		var rows [][]string
+		for _, v := range t.Values {
+			rows = append(rows, []string{fmt.Sprint(v.Number), v.Field, v.Description})
+		}
+		content = append(content, adfHeading(2, "Values"), adfTable([]string{"No.", "Value", "Description"}, rows))
+	}
+	return content
+}
+
+func adfFieldTable(fields []model.BodyField) []model.ADFNode {
+	if len(fields) == 0 {
+		return nil
+	}
+	var rows [][]string
+	for _, f := range fields {
+		rows = append(rows, []string{fmt.Sprint(f.Number), f.Field, f.Type, f.Mandatory, f.Description})
+	}
+	return []model.ADFNode{adfTable([]string{"No.", "Field", "Type", "Mandatory", "Description"}, rows)}
+}
+
+func adfAuthTable(auth []model.AuthData) model.ADFNode {
+	var rows [][]string
+	for _, a := range auth {
+		rows = append(rows, []string{a.Name, a.Type, a.Location, a.Scopes, a.Description})
+	}
+	return adfTable([]string{"Name", "Type", "Location", "Scopes", "Description"}, rows)
+}
+
+// adfBody renders a sample body as a code block, picking the language from
+// its content
+func adfBody(body string) []model.ADFNode {
+	body = strings.TrimSpace(body)
+	if body == "" {
+		return nil
+	}
+	return []model.ADFNode{adfCodeBlock(body, bodyLanguage(body))}
+}
+
+func adfDoc(content []model.ADFNode) model.ADFNode {
+	return model.ADFNode{Type: "doc", Version: 1, Content: content}
+}
+
+func adfHeading(level int, text string) model.ADFNode {
+	return model.ADFNode{
+		Type:    "heading",
+		Attrs:   map[string]interface{}{"level": level},
+		Content: adfTextLines(text),
+	}
+}
+
+func adfParagraph(content ...model.ADFNode) model.ADFNode {
+	return model.ADFNode{Type: "paragraph", Content: content}
+}
+
+// adfText returns a text node with the given marks. ADF rejects empty text
+// nodes, so callers only pass non-empty text
+func adfText(text string, marks ...string) model.ADFNode {
+	node := model.ADFNode{Type: "text", Text: text}
+	for _, mark := range marks {
+		node.Marks = append(node.Marks, model.ADFMark{Type: mark})
+	}
+	return node
+}
+
+// adfTextLines splits text on line breaks into text and hardBreak nodes
+func adfTextLines(text string, marks ...string) []model.ADFNode {
+	var nodes []model.ADFNode
+	for i, line := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n") {
+		if i > 0 {
+			nodes = append(nodes, model.ADFNode{Type: "hardBreak"})
+		}
+		if line != "" {
+			nodes = append(nodes, adfText(line, marks...))
+		}
+	}
+	return nodes
+}
+
+func adfCodeBlock(code string, language string) model.ADFNode {
+	node := model.ADFNode{Type: "codeBlock"}
+	if language != "" {
+		node.Attrs = map[string]interface{}{"language": language}
+	}
+	if code != "" {
+		node.Content = []model.ADFNode{adfText(code)}
+	}
+	return node
+}
+
+// adfTable renders a table with a header row; the first column after the
+// numbering is bold like the names in the storage format tables
+func adfTable(header []string, rows [][]string) model.ADFNode {
+	cell := func(cellType string, text string, marks ...string) model.ADFNode {
+		return model.ADFNode{Type: cellType, Content: []model.ADFNode{adfParagraph(adfTextLines(text, marks...)...)}}
+	}
+	bold := 0
+	if len(header) > 1 && header[0] == "No." {
+		bold = 1
+	}
+	headRow := model.ADFNode{Type: "tableRow"}
+	for _, h := range header {
+		headRow.Content = append(headRow.Content, cell("tableHeader", h))
+	}
+	table := model.ADFNode{
+		Type:    "table",
+		Attrs:   map[string]interface{}{"isNumberColumnEnabled": false, "layout": "default"},
+		Content: []model.ADFNode{headRow},
+	}
+	for _, row := range rows {
+		tr := model.ADFNode{Type: "tableRow"}
+		for i, value := range row {
+			if i == bold {
+				tr.Content = append(tr.Content, cell("tableCell", value, "strong"))
+			} else {
+				tr.Content = append(tr.Content, cell("tableCell", value))
+			}
+		}
+		table.Content = append(table.Content, tr)
+	}
+	return table
+}
@@ -0,0 +1,125 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/arifth/botthie/model"
+	"github.com/arifth/botthie/util"
+)
+
+// ProcessArchive imports every document found in an uploaded zip archive,
+// hands it to publish with the name of its file and reports the outcome per
+// file. publish returns the number of pages or files it produced. Every
+// document is imported with the whole archive as related files, so
+// environments, imported .proto files and XSD types resolve
+func ProcessArchive(data []byte, publish func(file string, doc model.APIDocument) (int, error)) ([]model.FileResult, error) {
+	files, err := util.ReadZip(data)
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var results []model.FileResult
+	for _, name := range names {
+		content := files[name]
+		switch {
+		case hasExt(name, ".json") && IsPostmanEnvironment(content):
+			results = append(results, model.FileResult{File: name, Kind: "Postman environment"})
+			continue
+		case hasExt(name, ".xsd"):
+			results = append(results, model.FileResult{File: name, Kind: "XML schema"})
+			continue
+		}
+
+		doc, importer, err := ImportDocument(name, content, files)
+		switch {
+		case errors.Is(err, ErrUnknownFormat):
+			results = append(results, model.FileResult{File: name, Kind: "unsupported", Err: fmt.Errorf("unsupported file type")})
+		case errors.Is(err, ErrNothingToDocument):
+			// supporting files such as imported .proto or .wsdl definitions
+			results = append(results, model.FileResult{File: name, Kind: importer.Name()})
+		case err != nil:
+			results = append(results, model.FileResult{File: name, Kind: importer.Name(), Err: err})
+		default:
+			pages, err := publish(name, doc)
+			results = append(results, model.FileResult{File: name, Kind: importer.Name(), Pages: pages, Err: err})
+		}
+	}
+	return results, nil
+}
+
+// ArchiveResults completes the per-file results of an archive with the
+// outcome of publishing its documents together, files[i] being the file of
+// the i-th document. A file only succeeds when every target published its
+// document: failures a target reports per document, such as the pages
+// Confluence rejected, count for the file of that document, failures of a
+// whole target for every file
+func ArchiveResults(results []model.FileResult, files []string, published []model.PublishResult) []model.FileResult {
+	for i, file := range files {
+		var errs []error
+		for _, p := range published {
+			if err := documentFailure(p.Err, i); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", p.Target, err))
+			}
+		}
+		for j := range results {
+			if results[j].File == file && len(errs) > 0 {
+				results[j].Err = errors.Join(append([]error{results[j].Err}, errs...)...)
+			}
+		}
+	}
+	return results
+}
+
+// documentFailure returns the part of the error of a target concerning the
+// document at index doc, nil when the target published it
+func documentFailure(err error, doc int) error {
+	var partial *PartialError
+	if !errors.As(err, &partial) {
+		return err
+	}
+	var failed []error
+	for _, f := range partial.Failed {
+		var docErr *DocumentError
+		switch {
+		case !errors.As(f, &docErr):
+			failed = append(failed, f)
+		case docErr.Index == doc:
+			failed = append(failed, docErr.Err)
+		}
+	}
+	return errors.Join(failed...)
+}
+
+// ArchiveSummary formats the per-file results of an archive as a chat message
+func ArchiveSummary(archive string, results []model.FileResult) string {
+	failed := 0
+	for _, r := range results {
+		if r.Err != nil {
+			failed++
+		}
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("📦 %s: %d file(s) processed, %d succeeded, %d failed\n", archive, len(results), len(results)-failed, failed))
+	for _, r := range results {
+		switch {
+		case r.Err != nil:
+			// joined errors of several targets stay on the line of the file
+			sb.WriteString(fmt.Sprintf("❌ %s (%s): %s\n", r.File, r.Kind, strings.ReplaceAll(r.Err.Error(), "\n", "; ")))
+		case r.Pages > 0:
+			sb.WriteString(fmt.Sprintf("✅ %s (%s): %d page(s)\n", r.File, r.Kind, r.Pages))
+		default:
+			sb.WriteString(fmt.Sprintf("✅ %s (%s)\n", r.File, r.Kind))
+		}
+	}
+	return strings.TrimSuffix(sb.String(), "\n")
+}
@@ -0,0 +1,158 @@
+package usecase
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/arifth/botthie/model"
+	"github.com/arifth/botthie/util"
+)
+
+// archiveFixture zips testdata fixtures with a broken collection and an
+// unsupported file
+func archiveFixture(t *testing.T) []byte {
+	t.Helper()
+	files := testdata(t)
+	archive, err := util.WriteZip(map[string][]byte{
+		"pets.postman_collection.json":     files["pets.postman_collection.json"],
+		"env/dev.postman_environment.json": files["dev.postman_environment.json"],
+		"openapi.yaml":                     files["openapi.yaml"],
+		"grpc/pets.proto":                  files["pets.proto"],
+		"grpc/common.proto":                files["common.proto"],
+		"soap/types.xsd":                   files["types.xsd"],
+		"broken.postman_collection.json":   []byte(`{"info": {"name": "Broken", "schema": "https://schema.getpostman.com/json/collection/v2.1.0/collection.json"}, "item": [{"name": ""}]}`),
+		"notes.txt":                        []byte("remember to document the pets"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return archive
+}
+
+// archiveErrors returns the error of every file of the results, empty when
+// the file succeeded
+func archiveErrors(results []model.FileResult) map[string]string {
+	errs := map[string]string{}
+	for _, r := range results {
+		errs[r.File] = ""
+		if r.Err != nil {
+			errs[r.File] = r.Err.Error()
+		}
+	}
+	return errs
+}
+
+func TestProcessArchiveReportsEveryFile(t *testing.T) {
+	var published []string
+	results, err := ProcessArchive(archiveFixture(t), func(file string, doc model.APIDocument) (int, error) {
+		published = append(published, file)
+		if file == "openapi.yaml" {
+			return 0, errors.New("space not found")
+		}
+		return PageCount(doc), nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"grpc/pets.proto", "openapi.yaml", "pets.postman_collection.json"}
+	if strings.Join(published, ",") != strings.Join(want, ",") {
+		t.Errorf("published %v, want %v", published, want)
+	}
+	kinds := map[string]string{}
+	for _, r := range results {
+		kinds[r.File] = r.Kind
+	}
+	wantKinds := map[string]string{
+		"broken.postman_collection.json":   "Postman collection",
+		"env/dev.postman_environment.json": "Postman environment",
+		"grpc/common.proto":                "gRPC proto file",
+		"grpc/pets.proto":                  "gRPC proto file",
+		"notes.txt":                        "unsupported",
+		"openapi.yaml":                     "OpenAPI document",
+		"pets.postman_collection.json":     "Postman collection",
+		"soap/types.xsd":                   "XML schema",
+	}
+	if len(kinds) != len(wantKinds) {
+		t.Errorf("results for %v, want %v", kinds, wantKinds)
+	}
+	for file, kind := range wantKinds {
+		if kinds[file] != kind {
+			t.Errorf("%s kind = %q, want %q", file, kinds[file], kind)
+		}
+	}
+
+	errs := archiveErrors(results)
+	for file, failed := range map[string]bool{
+		"broken.postman_collection.json": true,
+		"notes.txt":                      true,
+		"openapi.yaml":                   true,
+		"pets.postman_collection.json":   false,
+		"grpc/pets.proto":                false,
+		"grpc/common.proto":              false,
+	} {
+		if failed != (errs[file] != "") {
+			t.Errorf("%s error = %q, want failed %v", file, errs[file], failed)
+		}
+	}
+	if !strings.Contains(errs["openapi.yaml"], "space not found") {
+		t.Errorf("openapi.yaml error = %q, want the publishing error", errs["openapi.yaml"])
+	}
+
+	summary := ArchiveSummary("api.zip", results)
+	for _, line := range []string{
+		"📦 api.zip: 8 file(s) processed, 5 succeeded, 3 failed",
+		"❌ openapi.yaml (OpenAPI document): space not found",
+		"❌ notes.txt (unsupported): unsupported file type",
+		"✅ pets.postman_collection.json (Postman collection): 3 page(s)",
+		"✅ soap/types.xsd (XML schema)",
+	} {
+		if !strings.Contains(summary, line) {
+			t.Errorf("summary lacks %q:\n%s", line, summary)
+		}
+	}
+}
+
+func TestArchiveResultsReportsPublishingPerFile(t *testing.T) {
+	var docs []model.APIDocument
+	var files []string
+	results, err := ProcessArchive(archiveFixture(t), func(file string, doc model.APIDocument) (int, error) {
+		docs = append(docs, doc)
+		files = append(files, file)
+		return PageCount(doc), nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(docs) != 3 || files[2] != "pets.postman_collection.json" {
+		t.Fatalf("collected %v", files)
+	}
+
+	published := []model.PublishResult{
+		{Target: "WhatsApp md", Pages: 9},
+		// Confluence rejected one page of the collection only
+		{Target: "Confluence", Pages: 8, Err: &PartialError{Published: 8, Failed: []error{
+			&DocumentError{Doc: docs[2].Name, Index: 2, Err: errors.New("page Health: 400 Bad Request")},
+		}}},
+	}
+	errs := archiveErrors(ArchiveResults(results, files, published))
+	if !strings.Contains(errs["pets.postman_collection.json"], "Confluence: page Health: 400 Bad Request") {
+		t.Errorf("collection error = %q, want the rejected page", errs["pets.postman_collection.json"])
+	}
+	if errs["openapi.yaml"] != "" || errs["grpc/pets.proto"] != "" {
+		t.Errorf("documents Confluence published failed: %v", errs)
+	}
+
+	// a failure of the whole target fails every published file
+	published = append(published, model.PublishResult{Target: "Git", Err: errors.New("push rejected")})
+	errs = archiveErrors(ArchiveResults(results, files, published))
+	for _, file := range files {
+		if !strings.Contains(errs[file], "Git: push rejected") {
+			t.Errorf("%s error = %q, want the git failure", file, errs[file])
+		}
+	}
+	if errs["env/dev.postman_environment.json"] != "" {
+		t.Errorf("environment error = %q, it was not published", errs["env/dev.postman_environment.json"])
+	}
+}
@@ -0,0 +1,413 @@
+package usecase
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/arifth/botthie/model"
+	"gopkg.in/yaml.v3"
+)
+
+// decodeDocument decodes a YAML or JSON document into generic maps with
+// string keys
+func decodeDocument(data []byte) (map[string]interface{}, error) {
+	var raw interface{}
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+	doc, ok := normalizeYAML(raw).(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("document root is not an object")
+	}
+	return doc, nil
+}
+
+// normalizeYAML converts the map[interface{}]interface{} yaml produces for
+// non-string keys (e.g. status codes) into map[string]interface{}
+func normalizeYAML(node interface{}) interface{} {
+	switch v := node.(type) {
+	case map[string]interface{}:
+		for k, val := range v {
+			v[k] = normalizeYAML(val)
+		}
+		return v
+	case map[interface{}]interface{}:
+		res := make(map[string]interface{}, len(v))
+		for k, val := range v {
+			res[fmt.Sprint(k)] = normalizeYAML(val)
+		}
+		return res
+	case []interface{}:
+		for i, val := range v {
+			v[i] = normalizeYAML(val)
+		}
+		return v
+	default:
+		return v
+	}
+}
+
+// IsAsyncAPI reports whether data is an AsyncAPI document
+func IsAsyncAPI(data []byte) bool {
+	doc, err := decodeDocument(data)
+	if err != nil {
+		return false
+	}
+	_, ok := doc["asyncapi"]
+	return ok
+}
+
+// ParseAsyncAPI builds the documentation model from an AsyncAPI 2.x or 3.x
+// YAML or JSON document
+func ParseAsyncAPI(data []byte) (model.AsyncAPIDoc, error) {
+	doc, err := decodeDocument(data)
+	if err != nil {
+		return model.AsyncAPIDoc{}, fmt.Errorf("invalid AsyncAPI document: %w", err)
+	}
+	version, _ := doc["asyncapi"].(string)
+	if version == "" {
+		return model.AsyncAPIDoc{}, fmt.Errorf("missing asyncapi version field")
+	}
+
+	r := schemaResolver{root: doc}
+	info := r.deref(doc["info"])
+	res := model.AsyncAPIDoc{
+		Version:     version,
+		Title:       stringField(info, "title"),
+		APIVersion:  stringField(info, "version"),
+		Description: stringField(info, "description"),
+	}
+	if res.Title == "" {
+		res.Title = "AsyncAPI"
+	}
+
+	switch {
+	case strings.HasPrefix(version, "2."):
+		res.Operations = r.asyncOperationsV2(doc)
+	case strings.HasPrefix(version, "3."):
+		res.Operations = r.asyncOperationsV3(doc)
+	default:
+		return res, fmt.Errorf("unsupported AsyncAPI version %s", version)
+	}
+	if len(res.Operations) == 0 {
+		return res, fmt.Errorf("no channels or operations found")
+	}
+	return res, nil
+}
+
+func (r schemaResolver) asyncOperationsV2(doc map[string]interface{}) []model.AsyncOperation {
+	var ops []model.AsyncOperation
+	channels, _ := doc["channels"].(map[string]interface{})
+	for _, name := range sortedKeys(channels) {
+		channel := r.deref(channels[name])
+		if channel == nil {
+			continue
+		}
+		for _, action := range []string{"publish", "subscribe"} {
+			op := r.deref(channel[action])
+			if op == nil {
+				continue
+			}
+			res := model.AsyncOperation{
+				Channel:         name,
+				Address:         name,
+				Action:          action,
+				OperationID:     stringField(op, "operationId"),
+				Summary:         stringField(op, "summary"),
+				Description:     firstNonEmpty(stringField(op, "description"), stringField(channel, "description")),
+				Parameters:      r.channelParameters(channel),
+				Bindings:        r.bindings(op["bindings"]),
+				ChannelBindings: r.bindings(channel["bindings"]),
+			}
+			// a 2.x operation carries either one message or a oneOf list
+			msg := r.deref(op["message"])
+			if list, ok := msg["oneOf"].([]interface{}); ok {
+				for idx, m := range list {
+					res.Messages = append(res.Messages, r.asyncMessage(fmt.Sprintf("message%d", idx+1), m))
+				}
+			} else if msg != nil {
+				res.Messages = append(res.Messages, r.asyncMessage("message", msg))
+			}
+			ops = append(ops, res)
+		}
+	}
+	return ops
+}
+
+func (r schemaResolver) asyncOperationsV3(doc map[string]interface{}) []model.AsyncOperation {
+	var ops []model.AsyncOperation
+	channels, _ := doc["channels"].(map[string]interface{})
+	operations, _ := doc["operations"].(map[string]interface{})
+
+	documented := map[string]bool{}
+	for _, id := range sortedKeys(operations) {
+		op := r.deref(operations[id])
+		if op == nil {
+			continue
+		}
+		var channelRef map[string]interface{}
+		channelName := ""
+		if ref, ok := op["channel"].(map[string]interface{}); ok {
+			if s, ok := ref["$ref"].(string); ok {
+				channelName = strings.TrimPrefix(s, "#/channels/")
+			}
+			channelRef = r.deref(ref)
+		}
+		documented[channelName] = true
+
+		res := model.AsyncOperation{
+			Channel:         channelName,
+			Address:         firstNonEmpty(stringField(channelRef, "address"), channelName),
+			Action:          stringField(op, "action"),
+			OperationID:     id,
+			Summary:         firstNonEmpty(stringField(op, "summary"), stringField(op, "title")),
+			Description:     firstNonEmpty(stringField(op, "description"), stringField(channelRef, "description")),
+			Parameters:      r.channelParameters(channelRef),
+			Bindings:        r.bindings(op["bindings"]),
+			ChannelBindings: r.bindings(channelRef["bindings"]),
+		}
+
+		// operation messages are a subset of the channel messages; an
+		// operation without messages uses all of them
+		if list, ok := op["messages"].([]interface{}); ok && len(list) > 0 {
+			for _, m := range list {
+				name := ""
+				if obj, ok := m.(map[string]interface{}); ok {
+					if ref, ok := obj["$ref"].(string); ok {
+						name = ref[strings.LastIndex(ref, "/")+1:]
+					}
+				}
+				res.Messages = append(res.Messages, r.asyncMessage(name, m))
+			}
+		} else {
+			messages, _ := channelRef["messages"].(map[string]interface{})
+			for _, name := range sortedKeys(messages) {
+				res.Messages = append(res.Messages, r.asyncMessage(name, messages[name]))
+			}
+		}
+		ops = append(ops, res)
+	}
+
+	// channels no operation points at are still worth a page
+	for _, name := range sortedKeys(channels) {
+		if documented[name] {
+			continue
+		}
+		channel := r.deref(channels[name])
+		if channel == nil {
+			continue
+		}
+		res := model.AsyncOperation{
+			Channel:         name,
+			Address:         firstNonEmpty(stringField(channel, "address"), name),
+			Summary:         firstNonEmpty(stringField(channel, "summary"), stringField(channel, "title")),
+			Description:     stringField(channel, "description"),
+			Parameters:      r.channelParameters(channel),
+			ChannelBindings: r.bindings(channel["bindings"]),
+		}
+		messages, _ := channel["messages"].(map[string]interface{})
+		for _, msgName := range sortedKeys(messages) {
+			res.Messages = append(res.Messages, r.asyncMessage(msgName, messages[msgName]))
+		}
+		ops = append(ops, res)
+	}
+	return ops
+}
+
+func (r schemaResolver) asyncMessage(name string, node interface{}) model.AsyncMessage {
+	msg := r.deref(node)
+	res := model.AsyncMessage{
+		Name:        firstNonEmpty(stringField(msg, "name"), name),
+		Title:       stringField(msg, "title"),
+		Summary:     stringField(msg, "summary"),
+		Description: stringField(msg, "description"),
+		ContentType: stringField(msg, "contentType"),
+		Headers:     r.schemaFields(r.multiFormatSchema(msg["headers"])),
+		Payload:     r.schemaFields(r.multiFormatSchema(msg["payload"])),
+		Bindings:    r.bindings(msg["bindings"]),
+	}
+	return res
+}
+
+// multiFormatSchema unwraps the 3.x {schemaFormat, schema} wrapper
+func (r schemaResolver) multiFormatSchema(node interface{}) interface{} {
+	obj := r.deref(node)
+	if obj == nil {
+		return nil
+	}
+	if _, ok := obj["schemaFormat"]; ok {
+		if schema, ok := obj["schema"]; ok {
+			return schema
+		}
+	}
+	return obj
+}
+
+func (r schemaResolver) channelParameters(channel map[string]interface{}) []model.BodyField {
+	params, _ := channel["parameters"].(map[string]interface{})
+	var fields []model.BodyField
+	for _, name := range sortedKeys(params) {
+		param := r.deref(params[name])
+		description := stringField(param, "description")
+		paramType := "string"
+		if schema := r.deref(param["schema"]); schema != nil {
+			paramType = schemaType(r, schema)
+			if description == "" {
+				description = schemaDescription(schema)
+			}
+		}
+		fields = append(fields, model.BodyField{
+			Number:      len(fields) + 1,
+			Field:       name,
+			Type:        paramType,
+			Mandatory:   "Yes",
+			Description: description,
+		})
+	}
+	return fields
+}
+
+// bindings flattens protocol bindings into protocol / dotted key / value rows
+func (r schemaResolver) bindings(node interface{}) []model.AsyncBinding {
+	obj := r.deref(node)
+	var res []model.AsyncBinding
+	for _, protocol := range sortedKeys(obj) {
+		var walk func(prefix string, value interface{})
+		walk = func(prefix string, value interface{}) {
+			if m, ok := value.(map[string]interface{}); ok && m["$ref"] == nil {
+				for _, key := range sortedKeys(m) {
+					walk(strings.TrimPrefix(prefix+"."+key, "."), m[key])
+				}
+				return
+			}
+			res = append(res, model.AsyncBinding{
+				Protocol: protocol,
+				Key:      prefix,
+				Value:    scalarString(value),
+			})
+		}
+		walk("", obj[protocol])
+	}
+	return res
+}
+
+func stringField(obj map[string]interface{}, key string) string {
+	if obj == nil {
+		return ""
+	}
+	s, _ := obj[key].(string)
+	return s
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+type asyncAPIImporter struct{}
+
+func (asyncAPIImporter) Name() string { return "AsyncAPI document" }
+
+func (asyncAPIImporter) Detect(fileName string, data []byte) bool {
+	return isStructuredDocument(fileName) && IsAsyncAPI(data)
+}
+
+func (asyncAPIImporter) Import(fileName string, data []byte, related map[string][]byte) (model.APIDocument, error) {
+	doc, err := ParseAsyncAPI(data)
+	if err != nil {
+		return model.APIDocument{}, err
+	}
+	return asyncAPIToDocument(doc), nil
+}
+
+// asyncAPIToDocument maps every channel operation to an endpoint. A single
+// message becomes the body, several messages become the endpoint types
+func asyncAPIToDocument(doc model.AsyncAPIDoc) model.APIDocument {
+	res := model.APIDocument{
+		Name:        doc.Title,
+		Format:      asyncAPIImporter{}.Name(),
+		Description: doc.Description,
+		Version:     doc.APIVersion,
+	}
+	for _, op := range doc.Operations {
+		endpoint := model.APIEndpoint{
+			Name:        firstNonEmpty(op.OperationID, op.Channel),
+			Kind:        "AsyncAPI " + doc.Version,
+			Method:      strings.ToUpper(op.Action),
+			URL:         firstNonEmpty(op.Address, op.Channel),
+			Description: strings.TrimSpace(op.Summary + " " + op.Description),
+		}
+		for _, p := range op.Parameters {
+			endpoint.Parameters = append(endpoint.Parameters, model.APIParameter{
+				Name:        p.Field,
+				In:          "channel",
+				Type:        p.Type,
+				Required:    true,
+				Description: p.Description,
+			})
+		}
+		endpoint.Attributes = append(endpoint.Attributes, bindingAttributes("Channel", op.ChannelBindings)...)
+		endpoint.Attributes = append(endpoint.Attributes, bindingAttributes("Operation", op.Bindings)...)
+
+		if len(op.Messages) == 1 {
+			msg := op.Messages[0]
+			endpoint.Attributes = append(endpoint.Attributes, bindingAttributes("Message", msg.Bindings)...)
+			for _, h := range bodyFields(msg.Headers) {
+				endpoint.Headers = append(endpoint.Headers, model.APIParameter{
+					Name:        h.Name,
+					In:          "header",
+					Type:        h.Type,
+					Required:    h.Required,
+					Description: h.Description,
+				})
+			}
+			endpoint.Body = &model.APIBody{MediaType: msg.ContentType, Fields: bodyFields(msg.Payload)}
+			addEndpoint(&res, op.Channel, endpoint)
+			continue
+		}
+		for _, msg := range op.Messages {
+			description := strings.TrimSpace(firstNonEmpty(msg.Title, msg.Summary) + " " + msg.Description)
+			if msg.ContentType != "" {
+				description = strings.TrimSpace(fmt.Sprintf("%s (%s)", description, msg.ContentType))
+			}
+			var fields []model.APIField
+			for _, h := range bodyFields(msg.Headers) {
+				h.Name = "header " + h.Name
+				fields = append(fields, h)
+			}
+			endpoint.Types = append(endpoint.Types, model.APIType{
+				Name:        msg.Name,
+				Kind:        "Message",
+				Description: description,
+				Fields:      append(fields, bodyFields(msg.Payload)...),
+			})
+		}
+		addEndpoint(&res, op.Channel, endpoint)
+	}
+	return res
+}
+
+func bindingAttributes(scope string, bindings []model.AsyncBinding) []model.APIAttribute {
+	var attrs []model.APIAttribute
+	for _, b := range bindings {
+		attrs = append(attrs, model.APIAttribute{
+			Key:   fmt.Sprintf("%s binding %s %s", scope, b.Protocol, b.Key),
+			Value: b.Value,
+		})
+	}
+	return attrs
+}
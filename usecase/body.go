@@ -0,0 +1,20 @@
+package usecase
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// bodyLanguage returns json or xml for bodies that parse as such. Every
+// output labels its code blocks with it, so a templated body that is not
+// valid JSON is shown as text everywhere
+func bodyLanguage(body string) string {
+	body = strings.TrimSpace(body)
+	switch {
+	case json.Valid([]byte(body)) && (strings.HasPrefix(body, "{") || strings.HasPrefix(body, "[")):
+		return "json"
+	case strings.HasPrefix(body, "<"):
+		return "xml"
+	}
+	return ""
+}
@@ -1,105 +1,333 @@
 package usecase
 
 import (
-	"encoding/json"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"os"
+	"time"
 
 	"github.com/arifth/botthie/config"
+	"github.com/arifth/botthie/confluence"
 	"github.com/arifth/botthie/model"
-	"github.com/arifth/botthie/util"
-	"github.com/go-resty/resty/v2"
 )
 
 type ListSuccess struct {
-	success []resty.Response
-	error   []string
+	success []model.Content
+	// failed holds the pages, attachments and labels that failed while the
+	// others were published
+	failed []error
+	// links holds the parent page followed by the published children
+	links []model.PageLink
 }
 
-func (Usecase) PostBulkToConfluence(collection model.PostmanCollection, templ string, uc *Usecase) (ListSuccess, error) {
-	// iterate over collection item
-	//TODO : flatten the array
+// Links returns the web UI addresses of the published pages, the parent page
+// first
+func (l ListSuccess) Links() []model.PageLink {
+	return l.links
+}
 
-	// post parent conflu page
-	bodyReq := model.ConfluencePage{
-		Type:      "page",
-		Title:     "F105" + collection.Info.Name + "  " + util.GenerateRandomChars(),
-		Ancestors: []model.Ancestor{{ID: os.Getenv("PARENT_ID")}},
-		Space:     model.Space{Key: os.Getenv("SPACE_KEY")},
-		Body: model.BodyWrapper{
-			Storage: model.Storage{
-				Value:          "",
-				Representation: "storage",
-			},
-		},
+// PostDocumentToConfluence publishes one page per endpoint of every service
+// and one page per shared type of an imported document
+func (Usecase) PostDocumentToConfluence(doc model.APIDocument, templ model.Templates, uc *Usecase) (ListSuccess, error) {
+	// iterate over collection item
+	var pages []model.DocPage
+	keys := pathAllocator{}
+	generated := time.Now()
+	for _, service := range doc.Services {
+		for _, endpoint := range service.Endpoints {
+			title := endpoint.Name
+			if service.Name != "" {
+				title = service.Name + " " + endpoint.Name
+			}
+			properties := pageProperties(doc, endpoint, generated)
+			page := model.DocPage{
+				Key:         endpointPageKey(keys, service, endpoint),
+				Title:       title,
+				HTML:        uc.ConvertToStorage(doc, templ.Confluence, service, endpoint, properties),
+				Attachments: endpointAttachments(endpoint),
+				Group:       firstNonEmpty(service.Name, "Endpoints"),
+				Method:      endpoint.Method,
+				URL:         endpoint.URL,
+				Labels:      pageLabels(doc.Name, service.Name, endpoint.Method),
+			}
+			if confluenceADF() {
+				adf := uc.ConvertToADF(doc, service, endpoint)
+				adf.Content = append([]model.ADFNode{adfDetails(properties)}, adf.Content...)
+				page.ADF = ADFString(adf)
+			}
+			pages = append(pages, page)
+		}
 	}
-	type response struct {
-		ID     string `json:"id"`
-		Type   string `json:"type"`
-		Status string `json:"status"`
-		Title  string `json:"title"`
+	for _, t := range doc.Types {
+		page := model.DocPage{
+			Key:    keys.next("types", t.Name, ""),
+			Title:  fmt.Sprintf("%s %s", t.Kind, t.Name),
+			HTML:   uc.ConvertTypeToHTML(doc, templ.Type, t),
+			Group:  "Types",
+			Labels: pageLabels(doc.Name, "", ""),
+		}
+		if confluenceADF() {
+			page.ADF = ADFString(uc.ConvertTypeToADF(doc, t))
+		}
+		pages = append(pages, page)
 	}
-	postParent, err := PostToConfluence(bodyReq, true)
-	var res response
-	err = json.Unmarshal(postParent.Body(), &res)
-	if err != nil {
-		fmt.Println(err)
+	parent := model.DocPage{Title: doc.Name, Description: doc.Description, Labels: pageLabels(doc.Name, "", "")}
+	if doc.SourceName != "" {
+		// the uploaded collection is attached to the parent page
+		parent.Attachments = map[string][]byte{doc.SourceName: doc.Source}
 	}
-	parentID := res.ID
-	fmt.Println(parentID)
-	if err != nil {
-		fmt.Println(err)
+	return PostPagesToConfluence(firstNonEmpty(doc.ID, slugify(doc.Name)), parent, pages)
+}
+
+// endpointPageKey allocates the key of the page of an endpoint within its
+// document. Endpoints are allocated first, in order, so the keys are the same
+// wherever the document is published or read back
+func endpointPageKey(keys pathAllocator, service model.APIService, endpoint model.APIEndpoint) string {
+	return keys.next(slugify(firstNonEmpty(service.Name, "endpoints")), endpoint.Method+" "+endpoint.Name, "")
+}
+
+// endpointPageKeys returns the page keys of the endpoints of every service
+func endpointPageKeys(doc model.APIDocument) [][]string {
+	keys := pathAllocator{}
+	var pageKeys [][]string
+	for _, service := range doc.Services {
+		var serviceKeys []string
+		for _, endpoint := range service.Endpoints {
+			serviceKeys = append(serviceKeys, endpointPageKey(keys, service, endpoint))
+		}
+		pageKeys = append(pageKeys, serviceKeys)
 	}
-	list := ListSuccess{}
-	for _, item := range collection.Item {
-
-		html := uc.ConvertToHTML(collection, templ, item)
-
-		bodyReq := model.ConfluencePage{
-			Type:      "page",
-			Title:     item.Name + " " + util.GenerateRandomChars(),
-			Ancestors: []model.Ancestor{{ID: parentID}},
-			Space:     model.Space{Key: os.Getenv("SPACE_KEY")},
-			Body: model.BodyWrapper{
-				Storage: model.Storage{
-					Value:          html,
-					Representation: "storage",
-				},
-			},
+	return pageKeys
+}
+
+// confluencePageKey is the content property holding the key that identifies
+// a generated page across publications
+const confluencePageKey = "api-doc-key"
+
+// confluenceRef is an existing page found by its key
+type confluenceRef struct {
+	ID      string
+	Version int
+}
+
+// confluenceSpace is the API of a Confluence deployment used to publish page
+// trees
+type confluenceSpace interface {
+	// children returns the child pages of parentID by their key; pages
+	// without a key are left out
+	children(parentID string) (map[string]confluenceRef, error)
+	create(parentID string, key string, title string, page model.DocPage) (model.Content, error)
+	update(ref confluenceRef, parentID string, title string, page model.DocPage) (model.Content, error)
+	// attach uploads files to a page, adding a version to the attachments
+	// of the same name whose content changed
+	attach(pageID string, files map[string][]byte) error
+	// label adds labels to a page, keeping the ones it already has
+	label(pageID string, labels []string) error
+	// remove deletes a page, rolling back a page created without its key
+	remove(pageID string) error
+}
+
+// PostPagesToConfluence publishes the parent page under PARENT_ID and every
+// rendered page as its child, each with its attachments. Once the children
+// are published the parent is filled with an index linking to them. Pages are
+// identified by key, the id of the document, and the keys of the pages, so
+// publishing a document again updates its pages and only creates the new
+// ones. With CONFLUENCE_MODE=cloud the pages go through the Confluence Cloud
+// v2 API instead.
+//
+// Nothing is published when the parent page fails. Failures of the children
+// are returned as a *PartialError listing every failed item, next to the
+// pages that were published
+func PostPagesToConfluence(key string, parent model.DocPage, pages []model.DocPage) (ListSuccess, error) {
+	var space confluenceSpace = serverSpace{api: confluence.NewClient(serverClient(), "")}
+	if ConfluenceMode() == ConfluenceCloud {
+		cloud, err := newCloudSpace()
+		if err != nil {
+			return ListSuccess{}, err
 		}
-		//	//TODO: map value to struct
-		reqBody, err := json.Marshal(bodyReq)
+		space = cloud
+	}
+
+	list := ListSuccess{}
+	parentTitle := "F105" + parent.Title + "  " + pageSuffix(key)
+	roots, err := space.children(os.Getenv("PARENT_ID"))
+	if err != nil {
+		return list, err
+	}
+	parentRef, ok := roots[key]
+	parentLink := model.PageLink{Title: parent.Title}
+	if !ok {
+		// the index is filled in once the children exist
+		empty := model.DocPage{ADF: `{"type":"doc","version":1,"content":[]}`}
+		postParent, err := space.create(os.Getenv("PARENT_ID"), key, parentTitle, empty)
 		if err != nil {
-			fmt.Println("error when marshalling req body", err)
+			return list, err
 		}
-		resConflu, err := PostToConfluence(string(reqBody), false)
+		parentRef.ID = postParent.ID
+		parentRef.Version = 1
+		parentLink.URL = postParent.WebURL(os.Getenv("BASE_URL"))
+	}
+	if err := space.attach(parentRef.ID, parent.Attachments); err != nil {
+		list.failed = append(list.failed, pageFailure("attachments", parent.Title, err))
+	}
+	if err := space.label(parentRef.ID, parent.Labels); err != nil {
+		list.failed = append(list.failed, pageFailure("labels", parent.Title, err))
+	}
 
+	existing, err := space.children(parentRef.ID)
+	if err != nil {
+		return list, err
+	}
+	links := map[int]string{}
+	var childLinks []model.PageLink
+	for i, page := range pages {
+		pageKey := key + "/" + page.Key
+		var resConflu model.Content
+		if ref, ok := existing[pageKey]; ok {
+			resConflu, err = space.update(ref, parentRef.ID, childTitle(key, page), page)
+		} else {
+			resConflu, err = space.create(parentRef.ID, pageKey, childTitle(key, page), page)
+		}
 		if err != nil {
-			list.error = append(list.error, err.Error())
-			//SendMessage(client, chatJID, fmt.Sprintf("Failed to prepare API request: %v", err))
+			list.failed = append(list.failed, err)
+			continue
 		}
 		list.success = append(list.success, resConflu)
-		//link, err := getSpaceLinks(&resConflu)
+		links[i] = resConflu.WebURL(os.Getenv("BASE_URL"))
+		childLinks = append(childLinks, model.PageLink{Title: page.Title, URL: links[i]})
+		if err := space.attach(resConflu.ID, page.Attachments); err != nil {
+			list.failed = append(list.failed, pageFailure("attachments", page.Title, err))
+		}
+		if err := space.label(resConflu.ID, page.Labels); err != nil {
+			list.failed = append(list.failed, pageFailure("labels", page.Title, err))
+		}
+	}
 
+	index := confluenceIndex(key, parent, pages, links)
+	postIndex, err := space.update(parentRef, os.Getenv("PARENT_ID"), parentTitle, index)
+	if err != nil {
+		list.failed = append(list.failed, pageFailure("index", parent.Title, err))
+	} else {
+		parentLink.URL = postIndex.WebURL(os.Getenv("BASE_URL"))
+	}
+	list.links = append([]model.PageLink{parentLink}, childLinks...)
+	if len(list.failed) > 0 {
+		return list, &PartialError{Published: len(list.success), Failed: list.failed}
 	}
 	return list, nil
 }
 
-func PostToConfluence(data interface{}, isParent bool) (res resty.Response, err error) {
-	var baseURL = os.Getenv("BASE_URL")
-	var PAT_TOKEN = os.Getenv("PAT_TOKEN")
-	var hdrs = map[string]string{
-		"Authorization": fmt.Sprintf("Bearer %v", PAT_TOKEN),
+// rollback removes a page created without its key, since a later publication
+// could not find it and would create a duplicate
+func rollback(space confluenceSpace, pageID string, err error) error {
+	if removeErr := space.remove(pageID); removeErr != nil {
+		return errors.Join(err, removeErr)
 	}
+	return fmt.Errorf("%w, the page was removed again", err)
+}
+
+// childTitle returns the title of a child page, unique within the space
+func childTitle(key string, page model.DocPage) string {
+	return page.Title + " " + pageSuffix(key+"/"+page.Key)
+}
+
+// pageSuffix keeps the titles of pages unique within the space, as Confluence
+// requires, while staying the same across publications
+func pageSuffix(key string) string {
+	sum := sha1.Sum([]byte(key))
+	return hex.EncodeToString(sum[:])[:4]
+}
+
+// serverClient authenticates with PAT_TOKEN against the REST API in BASE_URL
+func serverClient() *config.Client {
+	return config.NewClient(&config.Config{
+		BaseURL: os.Getenv("BASE_URL"),
+		Headers: map[string]string{
+			"Authorization": fmt.Sprintf("Bearer %v", os.Getenv("PAT_TOKEN")),
+		},
+	})
+}
+
+// serverSpace publishes through the REST API of Confluence Server and Data
+// Center
+type serverSpace struct {
+	api *confluence.Client
+}
+
+func (s serverSpace) children(parentID string) (map[string]confluenceRef, error) {
+	return pageRefs(s.api, parentID)
+}
 
-	var conf = config.Config{
-		BaseURL: baseURL,
-		Headers: hdrs,
+// pageRefs lists the children of a page by key, with their version, through
+// the v1 API that expands both in a single listing
+func pageRefs(api *confluence.Client, parentID string) (map[string]confluenceRef, error) {
+	children, err := api.Children(parentID, "version", "metadata.properties."+confluencePageKey)
+	if err != nil {
+		return nil, err
 	}
+	refs := map[string]confluenceRef{}
+	for _, child := range children {
+		if child.Metadata == nil || child.Version == nil {
+			continue
+		}
+		if key := propertyKey(child.Metadata.Properties[confluencePageKey]); key != "" {
+			refs[key] = confluenceRef{ID: child.ID, Version: child.Version.Number}
+		}
+	}
+	return refs, nil
+}
 
-	fmt.Println(data)
+func (s serverSpace) create(parentID string, key string, title string, page model.DocPage) (model.Content, error) {
+	created, err := s.api.CreatePage(s.page(parentID, title, page))
+	if err != nil {
+		return created, err
+	}
+	if _, err := s.api.CreateProperty(created.ID, confluencePageKey, map[string]string{"key": key}); err != nil {
+		return created, rollback(s, created.ID, err)
+	}
+	return created, nil
+}
+
+func (s serverSpace) update(ref confluenceRef, parentID string, title string, page model.DocPage) (model.Content, error) {
+	body := s.page(parentID, title, page)
+	body.ID = ref.ID
+	body.Version = &model.Version{Number: ref.Version + 1}
+	return s.api.UpdatePage(body)
+}
+
+func (s serverSpace) remove(pageID string) error {
+	return s.api.DeletePage(pageID)
+}
+
+func (s serverSpace) attach(pageID string, files map[string][]byte) error {
+	return attachFiles(s.api, pageID, files)
+}
+
+func (s serverSpace) label(pageID string, labels []string) error {
+	_, err := s.api.AddLabels(pageID, labels...)
+	return err
+}
+
+func (serverSpace) page(parentID string, title string, page model.DocPage) model.Content {
+	return model.Content{
+		Type:      "page",
+		Title:     title,
+		Ancestors: []model.ContentRef{{ID: parentID}},
+		Space:     &model.ContentSpace{Key: os.Getenv("SPACE_KEY")},
+		Body: &model.ContentBody{
+			Storage: &model.ContentStorage{
+				Value:          page.HTML,
+				Representation: "storage",
+			},
+		},
+	}
+}
 
-	var clt = config.NewClient(&conf)
-	var resConflu, _ = clt.Post("/content/", data)
-	return *resConflu, nil
+// propertyKey reads the key stored in the content property of a page
+func propertyKey(prop model.ContentProperty) string {
+	value, _ := prop.Value.(map[string]interface{})
+	key, _ := value["key"].(string)
+	return key
 }
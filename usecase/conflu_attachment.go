@@ -0,0 +1,114 @@
+package usecase
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/arifth/botthie/confluence"
+	"github.com/arifth/botthie/model"
+)
+
+// jsonSchemaDialect is the JSON Schema version of the inferred schemas
+const jsonSchemaDialect = "https://json-schema.org/draft/2020-12/schema"
+
+// endpointAttachments returns the sample request and response bodies of an
+// endpoint as files, each JSON sample with the schema inferred from it
+func endpointAttachments(endpoint model.APIEndpoint) map[string][]byte {
+	files := map[string][]byte{}
+	names := pathAllocator{}
+	add := func(name string, body string) {
+		body = strings.TrimSpace(body)
+		if body == "" {
+			return
+		}
+		base := names.unique(name, "")
+		switch bodyLanguage(body) {
+		case "json":
+			files[base+".json"] = []byte(body + "\n")
+			if schema := sampleSchema(body); schema != nil {
+				files[base+".schema.json"] = schema
+			}
+		case "xml":
+			files[base+".xml"] = []byte(body + "\n")
+		default:
+			files[base+".txt"] = []byte(body + "\n")
+		}
+	}
+
+	if endpoint.Body != nil {
+		add("request", endpoint.Body.Raw)
+	}
+	for _, r := range endpoint.Responses {
+		if r.Body != nil {
+			add("response-"+slugify(r.Status), r.Body.Raw)
+		}
+	}
+	for _, e := range endpoint.Examples {
+		name := "example-" + slugify(firstNonEmpty(e.Name, e.Status))
+		add(name+"-request", e.Request)
+		add(name+"-response", e.Response)
+	}
+	return files
+}
+
+// sampleSchema infers a JSON schema document from a JSON sample
+func sampleSchema(body string) []byte {
+	var value interface{}
+	if err := json.Unmarshal([]byte(body), &value); err != nil {
+		return nil
+	}
+	schema := map[string]interface{}{"$schema": jsonSchemaDialect}
+	data, err := json.Marshal(inferSchema(value))
+	if err != nil || json.Unmarshal(data, &schema) != nil {
+		return nil
+	}
+	out, err := json.MarshalIndent(schema, "", "  ")
+	if err != nil {
+		return nil
+	}
+	return append(out, '\n')
+}
+
+// attachFiles uploads files to a page in the order of their names. The
+// comment of each attachment records the hash of its content, so
+// republishing skips the files that did not change instead of adding a new
+// version of them
+func attachFiles(api *confluence.Client, pageID string, files map[string][]byte) error {
+	existing, err := api.Attachments(pageID)
+	if err != nil {
+		return err
+	}
+	comments := map[string]string{}
+	for _, a := range existing {
+		if a.Extensions != nil {
+			comments[a.Title] = a.Extensions.Comment
+		}
+	}
+
+	var names []string
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var failed []error
+	for _, name := range names {
+		comment := attachmentComment(files[name])
+		if comments[name] == comment {
+			continue
+		}
+		if _, err := api.Attach(pageID, name, comment, files[name]); err != nil {
+			failed = append(failed, err)
+		}
+	}
+	return errors.Join(failed...)
+}
+
+// attachmentComment identifies the content of an attachment by its hash
+func attachmentComment(data []byte) string {
+	return fmt.Sprintf("sha256:%x", sha256.Sum256(data))
+}
@@ -0,0 +1,60 @@
+package usecase
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/arifth/botthie/config"
+	"github.com/arifth/botthie/confluence"
+	"github.com/arifth/botthie/model"
+)
+
+func TestAttachFilesSkipsUnchangedFiles(t *testing.T) {
+	unchanged := []byte(`{"id":1}` + "\n")
+	var mu sync.Mutex
+	var uploaded []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/content/42/child/attachment" {
+			http.NotFound(w, r)
+			return
+		}
+		if r.Method == http.MethodGet {
+			json.NewEncoder(w).Encode(model.ResultPage[model.Content]{Results: []model.Content{
+				{ID: "a1", Title: "request.json", Extensions: &model.AttachmentExtensions{Comment: attachmentComment(unchanged)}},
+				{ID: "a2", Title: "response-200.json", Extensions: &model.AttachmentExtensions{Comment: attachmentComment([]byte("old"))}},
+			}})
+			return
+		}
+		file, header, err := r.FormFile("file")
+		if err != nil {
+			t.Errorf("upload without a file: %v", err)
+			return
+		}
+		file.Close()
+		if comment := r.FormValue("comment"); comment == "" {
+			t.Errorf("%s uploaded without its hash", header.Filename)
+		}
+		mu.Lock()
+		uploaded = append(uploaded, header.Filename)
+		mu.Unlock()
+		json.NewEncoder(w).Encode(model.ResultPage[model.Content]{Results: []model.Content{{Title: header.Filename}}})
+	}))
+	defer srv.Close()
+
+	api := confluence.NewClient(config.NewClient(&config.Config{BaseURL: srv.URL}), "")
+	err := attachFiles(api, "42", map[string][]byte{
+		"request.json":      unchanged,
+		"response-200.json": []byte("new"),
+		"collection.json":   []byte("{}"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"collection.json", "response-200.json"}; !reflect.DeepEqual(uploaded, want) {
+		t.Errorf("uploaded %v, want %v", uploaded, want)
+	}
+}
@@ -0,0 +1,151 @@
+package usecase
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/arifth/botthie/config"
+	"github.com/arifth/botthie/confluence"
+	"github.com/arifth/botthie/model"
+)
+
+// Confluence deployments selected with CONFLUENCE_MODE
+const (
+	ConfluenceServer = "server"
+	ConfluenceCloud  = "cloud"
+)
+
+// ConfluenceMode returns the configured deployment, Server/Data Center by
+// default
+func ConfluenceMode() string {
+	if strings.EqualFold(os.Getenv("CONFLUENCE_MODE"), ConfluenceCloud) {
+		return ConfluenceCloud
+	}
+	return ConfluenceServer
+}
+
+// confluenceADF reports whether Cloud pages are published as Atlassian
+// Document Format instead of storage format XHTML
+func confluenceADF() bool {
+	return ConfluenceMode() == ConfluenceCloud && strings.EqualFold(os.Getenv("CONFLUENCE_FORMAT"), "adf")
+}
+
+// cloudClient authenticates with CONFLUENCE_EMAIL and CONFLUENCE_API_TOKEN
+// against the site in BASE_URL, e.g. https://example.atlassian.net/wiki
+func cloudClient() *config.Client {
+	return config.NewClient(&config.Config{
+		BaseURL:  strings.TrimSuffix(os.Getenv("BASE_URL"), "/"),
+		Username: os.Getenv("CONFLUENCE_EMAIL"),
+		Password: os.Getenv("CONFLUENCE_API_TOKEN"),
+	})
+}
+
+// cloudSpaceID returns SPACE_ID, or looks up the id of SPACE_KEY since the
+// v2 API addresses spaces by id
+func cloudSpaceID(clt *config.Client) (string, error) {
+	if id := os.Getenv("SPACE_ID"); id != "" {
+		return id, nil
+	}
+	key := os.Getenv("SPACE_KEY")
+	res, err := clt.Get("/api/v2/spaces?keys=" + url.QueryEscape(key))
+	if err := confluence.ResponseError("look up space", key, res, err); err != nil {
+		return "", err
+	}
+	var spaces struct {
+		Results []struct {
+			ID string `json:"id"`
+		} `json:"results"`
+	}
+	if err := json.Unmarshal(res.Body(), &spaces); err != nil {
+		return "", err
+	}
+	if len(spaces.Results) == 0 {
+		return "", fmt.Errorf("space %s not found", key)
+	}
+	return spaces.Results[0].ID, nil
+}
+
+// cloudSpace publishes through the Confluence Cloud v2 API, and through the
+// v1 API for what v2 cannot do
+type cloudSpace struct {
+	clt     *config.Client
+	v1      *confluence.Client
+	spaceID string
+}
+
+func newCloudSpace() (cloudSpace, error) {
+	clt := cloudClient()
+	spaceID, err := cloudSpaceID(clt)
+	return cloudSpace{clt: clt, v1: confluence.NewClient(clt, "/rest/api"), spaceID: spaceID}, err
+}
+
+// children goes through the v1 API, the v2 API neither expands the
+// properties nor the version of the pages it lists
+func (s cloudSpace) children(parentID string) (map[string]confluenceRef, error) {
+	return pageRefs(s.v1, parentID)
+}
+
+func (s cloudSpace) create(parentID string, key string, title string, page model.DocPage) (model.Content, error) {
+	res, err := s.clt.Post("/api/v2/pages", s.page(parentID, title, page))
+	if err := confluence.ResponseError("create page", title, res, err); err != nil {
+		return model.Content{}, err
+	}
+	var created model.Content
+	if err := json.Unmarshal(res.Body(), &created); err != nil {
+		return created, err
+	}
+	prop, err := s.clt.Post("/api/v2/pages/"+created.ID+"/properties", model.ContentProperty{
+		Key:   confluencePageKey,
+		Value: map[string]string{"key": key},
+	})
+	if err := confluence.ResponseError("store the key of page", title, prop, err); err != nil {
+		return created, rollback(s, created.ID, err)
+	}
+	return created, nil
+}
+
+func (s cloudSpace) update(ref confluenceRef, parentID string, title string, page model.DocPage) (model.Content, error) {
+	body := s.page(parentID, title, page)
+	body.ID = ref.ID
+	body.Version = &model.Version{Number: ref.Version + 1}
+	res, err := s.clt.Put("/api/v2/pages/"+ref.ID, body)
+	if err := confluence.ResponseError("update page", title, res, err); err != nil {
+		return model.Content{}, err
+	}
+	var updated model.Content
+	err = json.Unmarshal(res.Body(), &updated)
+	return updated, err
+}
+
+func (s cloudSpace) remove(pageID string) error {
+	res, err := s.clt.Delete("/api/v2/pages/" + pageID)
+	return confluence.ResponseError("remove page", pageID, res, err)
+}
+
+// attach goes through the v1 API, the v2 API cannot upload attachments
+func (s cloudSpace) attach(pageID string, files map[string][]byte) error {
+	return attachFiles(s.v1, pageID, files)
+}
+
+// label goes through the v1 API, the v2 API can only read labels
+func (s cloudSpace) label(pageID string, labels []string) error {
+	_, err := s.v1.AddLabels(pageID, labels...)
+	return err
+}
+
+func (s cloudSpace) page(parentID string, title string, page model.DocPage) model.CloudPage {
+	body := model.CloudBody{Representation: "storage", Value: page.HTML}
+	if confluenceADF() {
+		body = model.CloudBody{Representation: "atlas_doc_format", Value: page.ADF}
+	}
+	return model.CloudPage{
+		SpaceID:  s.spaceID,
+		Status:   "current",
+		Title:    title,
+		ParentID: parentID,
+		Body:     body,
+	}
+}
@@ -0,0 +1,282 @@
+package usecase
+
+import (
+	"encoding/xml"
+	"strings"
+
+	"github.com/arifth/botthie/confluence"
+	"github.com/arifth/botthie/model"
+)
+
+// PageEdits holds what reviewers correct on a published endpoint page: the
+// description and the mandatory flags and descriptions of the request body
+// fields, by field name
+type PageEdits struct {
+	Description string
+	Fields      map[string]model.BodyField
+}
+
+// apply replaces the description and body fields of an endpoint with the
+// ones reviewers changed. published is the endpoint as it was published,
+// values still equal to it are left alone, since the published pages may
+// hold environment values the export must not contain
+func (e PageEdits) apply(req *model.RequestData, published model.RequestData) {
+	if e.Description != "" && e.Description != strings.TrimSpace(published.Description) {
+		req.Description = e.Description
+	}
+	generated := map[string]model.BodyField{}
+	for _, f := range published.BodyFields {
+		generated[f.Field] = f
+	}
+	for i, f := range req.BodyFields {
+		edited, ok := e.Fields[f.Field]
+		if !ok {
+			continue
+		}
+		if edited.Mandatory != "" && edited.Mandatory != generated[f.Field].Mandatory {
+			req.BodyFields[i].Mandatory = edited.Mandatory
+		}
+		if edited.Description != "" && edited.Description != strings.Join(strings.Fields(generated[f.Field].Description), " ") {
+			req.BodyFields[i].Description = edited.Description
+		}
+	}
+}
+
+// confluenceAPI returns the v1 REST client of the configured deployment
+func confluenceAPI() *confluence.Client {
+	if ConfluenceMode() == ConfluenceCloud {
+		return confluence.NewClient(cloudClient(), "/rest/api")
+	}
+	return confluence.NewClient(serverClient(), "")
+}
+
+// ReviewedEdits reads the edits reviewers made on the published pages of a
+// document, by the key of each page
+type ReviewedEdits func(doc model.APIDocument) (map[string]PageEdits, error)
+
+// ConfluenceEdits reads the endpoint pages of the documents published below
+// parentID back from Confluence. Nothing is read for a document that was
+// never published
+func ConfluenceEdits(parentID string) ReviewedEdits {
+	return func(doc model.APIDocument) (map[string]PageEdits, error) {
+		api := confluenceAPI()
+		expandKey := "metadata.properties." + confluencePageKey
+		key := firstNonEmpty(doc.ID, slugify(doc.Name))
+		roots, err := api.Children(parentID, expandKey)
+		if err != nil {
+			return nil, err
+		}
+		for _, root := range roots {
+			if contentKey(root) != key {
+				continue
+			}
+			children, err := api.Children(root.ID, "body.storage", expandKey)
+			if err != nil {
+				return nil, err
+			}
+			edits := map[string]PageEdits{}
+			for _, child := range children {
+				pageKey, ok := strings.CutPrefix(contentKey(child), key+"/")
+				if !ok || child.Body == nil || child.Body.Storage == nil {
+					continue
+				}
+				edits[pageKey] = storageEdits(child.Body.Storage.Value)
+			}
+			return edits, nil
+		}
+		return nil, nil
+	}
+}
+
+// contentKey reads the key property of a page listed with its properties
+func contentKey(c model.Content) string {
+	if c.Metadata == nil {
+		return ""
+	}
+	return propertyKey(c.Metadata.Properties[confluencePageKey])
+}
+
+// storageNode is an element, or a text when Name is empty, of a page in
+// storage format
+type storageNode struct {
+	Name     string
+	Attr     map[string]string
+	Text     string
+	Children []*storageNode
+}
+
+// text returns the text of a node and its descendants, line breaks
+// included
+func (n *storageNode) text() string {
+	switch n.Name {
+	case "":
+		return n.Text
+	case "br":
+		return "\n"
+	}
+	var sb strings.Builder
+	for _, c := range n.Children {
+		sb.WriteString(c.text())
+	}
+	return sb.String()
+}
+
+// parseStorage reads a page in storage format into a tree. The ac: and ri:
+// prefixes are not declared, so only the local names are kept
+func parseStorage(body string) *storageNode {
+	dec := xml.NewDecoder(strings.NewReader(body))
+	dec.Strict = false
+	dec.AutoClose = xml.HTMLAutoClose
+	dec.Entity = xml.HTMLEntity
+	root := &storageNode{Name: "document"}
+	stack := []*storageNode{root}
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			return root
+		}
+		top := stack[len(stack)-1]
+		switch t := tok.(type) {
+		case xml.StartElement:
+			node := &storageNode{Name: t.Name.Local, Attr: map[string]string{}}
+			for _, a := range t.Attr {
+				node.Attr[a.Name.Local] = a.Value
+			}
+			top.Children = append(top.Children, node)
+			stack = append(stack, node)
+		case xml.EndElement:
+			if len(stack) > 1 {
+				stack = stack[:len(stack)-1]
+			}
+		case xml.CharData:
+			top.Children = append(top.Children, &storageNode{Text: string(t)})
+		}
+	}
+}
+
+// storageEdits reads the description and the request body fields of an
+// endpoint page. Pages rendered with the Confluence template hold the
+// description in an info panel. Cloud pages published as ADF read back as
+// the storage format Confluence converts them to, where the description is
+// the plain paragraphs below the endpoint heading. The fields come from the
+// field table of the Request Body section in both
+func storageEdits(body string) PageEdits {
+	edits := PageEdits{Fields: map[string]model.BodyField{}}
+	section, level := "", 0
+	var info, paragraphs []string
+	var walk func(n *storageNode, top bool)
+	walk = func(n *storageNode, top bool) {
+		switch {
+		case len(n.Name) == 2 && n.Name[0] == 'h' && n.Name[1] >= '1' && n.Name[1] <= '6':
+			section, level = strings.Join(strings.Fields(n.text()), " "), int(n.Name[1]-'0')
+			return
+		case n.Name == "structured-macro" && n.Attr["name"] == "info":
+			if info == nil {
+				info = storageParagraphs(findNodes(n, "p"))
+			}
+			return
+		case n.Name == "p" && top && level == 3 && !labelled(n):
+			paragraphs = append(paragraphs, storageParagraphs([]*storageNode{n})...)
+			return
+		case n.Name == "table" && strings.EqualFold(section, "Request Body"):
+			for _, f := range tableFields(n) {
+				edits.Fields[f.Field] = f
+			}
+			return
+		}
+		for _, c := range n.Children {
+			walk(c, false)
+		}
+	}
+	for _, c := range parseStorage(body).Children {
+		walk(c, true)
+	}
+	if info == nil {
+		info = paragraphs
+	}
+	edits.Description = strings.Join(info, "\n")
+	return edits
+}
+
+// storageParagraphs returns the trimmed text of the non-empty paragraphs
+func storageParagraphs(paragraphs []*storageNode) []string {
+	var texts []string
+	for _, p := range paragraphs {
+		if text := strings.TrimSpace(p.text()); text != "" {
+			texts = append(texts, text)
+		}
+	}
+	return texts
+}
+
+// labelled reports whether a paragraph starts with a bold label, like the
+// Service and Deprecated lines of an ADF page
+func labelled(p *storageNode) bool {
+	for _, c := range p.Children {
+		if c.Name == "" && strings.TrimSpace(c.Text) == "" {
+			continue
+		}
+		return c.Name == "strong" || c.Name == "b"
+	}
+	return false
+}
+
+// tableFields reads the rows of a field table. The columns are found by
+// their header, since reviewers may reorder or add columns
+func tableFields(table *storageNode) []model.BodyField {
+	rows := findNodes(table, "tr")
+	if len(rows) == 0 {
+		return nil
+	}
+	columns := map[string]int{}
+	for i, cell := range tableCells(rows[0]) {
+		columns[strings.ToLower(cell)] = i
+	}
+	field, ok := columns["field"]
+	if !ok {
+		return nil
+	}
+	cell := func(cells []string, name string) string {
+		if i, ok := columns[name]; ok && i < len(cells) {
+			return cells[i]
+		}
+		return ""
+	}
+	var fields []model.BodyField
+	for _, row := range rows[1:] {
+		cells := tableCells(row)
+		if field >= len(cells) || cells[field] == "" {
+			continue
+		}
+		fields = append(fields, model.BodyField{
+			Field:       cells[field],
+			Mandatory:   cell(cells, "mandatory"),
+			Description: cell(cells, "description"),
+		})
+	}
+	return fields
+}
+
+// tableCells returns the text of the header and data cells of a row
+func tableCells(row *storageNode) []string {
+	var cells []string
+	for _, c := range row.Children {
+		if c.Name == "th" || c.Name == "td" {
+			cells = append(cells, strings.Join(strings.Fields(c.text()), " "))
+		}
+	}
+	return cells
+}
+
+// findNodes returns the descendants of n named name, in document order
+func findNodes(n *storageNode, name string) []*storageNode {
+	var found []*storageNode
+	for _, c := range n.Children {
+		if c.Name == name {
+			found = append(found, c)
+			continue
+		}
+		found = append(found, findNodes(c, name)...)
+	}
+	return found
+}
@@ -0,0 +1,223 @@
+package usecase
+
+import (
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/arifth/botthie/model"
+)
+
+// editsEndpoint is the endpoint rendered by the page fixtures
+func editsEndpoint(description string) (model.APIDocument, model.APIService, model.APIEndpoint) {
+	endpoint := model.APIEndpoint{
+		Name:        "Create pet",
+		Method:      "POST",
+		URL:         "https://api.example.com/pets",
+		Description: description,
+		Body: &model.APIBody{
+			Raw: `{"name":"Rex","tag":"dog"}`,
+			Fields: []model.APIField{
+				{Name: "name", Type: "string", Required: true, Description: "Name of the pet"},
+				{Name: "tag", Type: "string"},
+			},
+		},
+		Responses: []model.APIResponse{{
+			Status: "201",
+			Body: &model.APIBody{Fields: []model.APIField{
+				{Name: "id", Type: "number", Required: true, Description: "Id of the pet"},
+			}},
+		}},
+	}
+	service := model.APIService{Name: "Pets", Endpoints: []model.APIEndpoint{endpoint}}
+	return model.APIDocument{Name: "Pet Store", Services: []model.APIService{service}}, service, endpoint
+}
+
+// renderConfluencePage renders the endpoint with template/confluence.html,
+// edited first with edit to play the changes of a reviewer
+func renderConfluencePage(t *testing.T, description string, edit *strings.Replacer) string {
+	t.Helper()
+	templ, err := os.ReadFile("../template/confluence.html")
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc, service, endpoint := editsEndpoint(description)
+	return Usecase{}.ConvertToStorage(doc, edit.Replace(string(templ)), service, endpoint, nil)
+}
+
+// adfPage is the storage format Confluence Cloud returns for the endpoint
+// page published as ADF by ConvertToADF
+const adfPage = `<h1>Pet Store</h1><h3>Create pet</h3><p><strong>Service: </strong>Pets</p>` +
+	`<ac:structured-macro ac:name="code" ac:schema-version="1" ac:macro-id="4f1c"><ac:plain-text-body><![CDATA[POST https://api.example.com/pets]]></ac:plain-text-body></ac:structured-macro>` +
+	`<p>Creates a pet.<br />Requires the <code>pets:write</code> scope.</p>` +
+	`<p><strong>Deprecated: </strong>use v2</p>` +
+	`<h2>Request Body</h2>` +
+	`<table data-table-width="760" data-layout="default" ac:local-id="9a0e"><colgroup><col style="width: 40.0px;" /><col style="width: 180.0px;" /><col style="width: 120.0px;" /><col style="width: 100.0px;" /><col style="width: 320.0px;" /></colgroup>` +
+	`<tbody><tr><th><p>No.</p></th><th><p>Field</p></th><th><p>Type</p></th><th><p>Mandatory</p></th><th><p>Description</p></th></tr>` +
+	`<tr><td><p>1</p></td><td><p><strong>name</strong></p></td><td><p>string</p></td><td><p>Yes</p></td><td><p>Name of the pet &amp; its nickname</p></td></tr>` +
+	`<tr><td><p>2</p></td><td><p><strong>tag</strong></p></td><td><p>string</p></td><td><p>Yes</p></td><td><p /></td></tr></tbody></table>` +
+	`<ac:structured-macro ac:name="code" ac:schema-version="1" ac:macro-id="77d2"><ac:parameter ac:name="language">json</ac:parameter><ac:plain-text-body><![CDATA[{"name":"Rex","tag":"dog"}]]></ac:plain-text-body></ac:structured-macro>` +
+	`<h2>Response 201</h2>` +
+	`<table data-layout="default"><tbody><tr><th><p>No.</p></th><th><p>Field</p></th><th><p>Type</p></th><th><p>Mandatory</p></th><th><p>Description</p></th></tr>` +
+	`<tr><td><p>1</p></td><td><p><strong>id</strong></p></td><td><p>number</p></td><td><p>No</p></td><td><p>Id</p></td></tr></tbody></table>`
+
+func TestStorageEdits(t *testing.T) {
+	published := map[string]model.BodyField{
+		"name": {Field: "name", Mandatory: "Yes", Description: "Name of the pet"},
+		"tag":  {Field: "tag", Mandatory: "No"},
+	}
+	tests := []struct {
+		name string
+		body string
+		want PageEdits
+	}{
+		{
+			name: "template output",
+			body: renderConfluencePage(t, "Creates a pet.", strings.NewReplacer()),
+			want: PageEdits{Description: "Creates a pet.", Fields: published},
+		},
+		{
+			name: "reviewed description and mandatory flag",
+			body: strings.NewReplacer(
+				"<p>Creates a pet.</p>", "<p>Creates a pet in the <strong>store</strong>.</p>\n<p>Needs a token.</p>",
+				"<span>No</span>", "<span>Yes</span>",
+			).Replace(renderConfluencePage(t, "Creates a pet.", strings.NewReplacer())),
+			want: PageEdits{Description: "Creates a pet in the store.\nNeeds a token.", Fields: map[string]model.BodyField{
+				"name": published["name"],
+				"tag":  {Field: "tag", Mandatory: "Yes"},
+			}},
+		},
+		{
+			name: "reordered columns",
+			body: renderConfluencePage(t, "Creates a pet.", strings.NewReplacer(
+				`<th style="width: 10%;">Mandatory</th>`, `<th style="width: 50%;">Description</th>`,
+				`<th style="width: 50%;">Description</th>`, `<th style="width: 10%;">Mandatory</th>`,
+				`<span>{{.Mandatory}}</span>`, `<span>{{html .Description}}</span>`,
+				`<td>{{html .Description}}</td>`, `<td>{{.Mandatory}}</td>`,
+			)),
+			want: PageEdits{Description: "Creates a pet.", Fields: published},
+		},
+		{
+			name: "extra column",
+			body: renderConfluencePage(t, "Creates a pet.", strings.NewReplacer(
+				`<th style="width: 20%;">Field</th>`, `<th style="width: 20%;">Field</th><th>Reviewer notes</th>`,
+				`<td><strong>{{html .Field}}</strong></td>`, `<td><strong>{{html .Field}}</strong></td><td>checked</td>`,
+			)),
+			want: PageEdits{Description: "Creates a pet.", Fields: published},
+		},
+		{
+			name: "missing description",
+			body: renderConfluencePage(t, "", strings.NewReplacer(
+				`<th style="width: 50%;">Description</th>`, "",
+				`<td>{{html .Description}}</td>`, "",
+			)),
+			want: PageEdits{Fields: map[string]model.BodyField{
+				"name": {Field: "name", Mandatory: "Yes"},
+				"tag":  {Field: "tag", Mandatory: "No"},
+			}},
+		},
+		{
+			name: "no field column",
+			body: renderConfluencePage(t, "Creates a pet.", strings.NewReplacer(
+				`<th style="width: 20%;">Field</th>`, `<th style="width: 20%;">Name</th>`,
+			)),
+			want: PageEdits{Description: "Creates a pet.", Fields: map[string]model.BodyField{}},
+		},
+		{
+			name: "cloud page converted from ADF",
+			body: adfPage,
+			want: PageEdits{Description: "Creates a pet.\nRequires the pets:write scope.", Fields: map[string]model.BodyField{
+				"name": {Field: "name", Mandatory: "Yes", Description: "Name of the pet & its nickname"},
+				"tag":  {Field: "tag", Mandatory: "Yes"},
+			}},
+		},
+		{
+			name: "not a page",
+			body: "plain text <unclosed",
+			want: PageEdits{Fields: map[string]model.BodyField{}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := storageEdits(tt.body); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("storageEdits = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPageEditsApply(t *testing.T) {
+	// the export keeps the variables, the published page had them resolved
+	export := model.RequestData{
+		Description: "Send {{token}} as bearer",
+		BodyFields: []model.BodyField{
+			{Field: "name", Mandatory: "Yes", Description: "Name, e.g. {{petName}}"},
+			{Field: "tag", Mandatory: "No", Description: "Tag of the pet"},
+			{Field: "owner", Mandatory: "No", Description: "Owner of the pet"},
+		},
+	}
+	published := model.RequestData{
+		Description: "Send SECRET-123 as bearer\n",
+		BodyFields: []model.BodyField{
+			{Field: "name", Mandatory: "Yes", Description: "Name, e.g.  Rex"},
+			{Field: "tag", Mandatory: "No", Description: "Tag of the pet"},
+			{Field: "owner", Mandatory: "No", Description: "Owner of the pet"},
+		},
+	}
+
+	tests := []struct {
+		name  string
+		edits PageEdits
+		want  model.RequestData
+	}{
+		{
+			name: "published values are kept",
+			edits: PageEdits{Description: "Send SECRET-123 as bearer", Fields: map[string]model.BodyField{
+				"name":  {Field: "name", Mandatory: "Yes", Description: "Name, e.g. Rex"},
+				"tag":   {Field: "tag", Mandatory: "No", Description: "Tag of the pet"},
+				"owner": {Field: "owner", Mandatory: "No", Description: "Owner of the pet"},
+			}},
+			want: export,
+		},
+		{
+			name: "reviewed values replace the generated ones",
+			edits: PageEdits{Description: "Send the session token as bearer", Fields: map[string]model.BodyField{
+				"name": {Field: "name", Mandatory: "Yes", Description: "Name, e.g. Rex"},
+				"tag":  {Field: "tag", Mandatory: "Yes", Description: "Species of the pet"},
+			}},
+			want: model.RequestData{
+				Description: "Send the session token as bearer",
+				BodyFields: []model.BodyField{
+					{Field: "name", Mandatory: "Yes", Description: "Name, e.g. {{petName}}"},
+					{Field: "tag", Mandatory: "Yes", Description: "Species of the pet"},
+					{Field: "owner", Mandatory: "No", Description: "Owner of the pet"},
+				},
+			},
+		},
+		{
+			name: "empty values are kept",
+			edits: PageEdits{Fields: map[string]model.BodyField{
+				"tag": {Field: "tag"},
+			}},
+			want: export,
+		},
+		{
+			name: "fields unknown to the collection are ignored",
+			edits: PageEdits{Fields: map[string]model.BodyField{
+				"color": {Field: "color", Mandatory: "Yes", Description: "Color of the pet"},
+			}},
+			want: export,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := export
+			req.BodyFields = append([]model.BodyField(nil), export.BodyFields...)
+			tt.edits.apply(&req, published)
+			if !reflect.DeepEqual(req, tt.want) {
+				t.Errorf("apply = %+v, want %+v", req, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,31 @@
+package usecase
+
+import (
+	"fmt"
+	"strings"
+)
+
+// PartialError reports the items of a publication that failed while the
+// other pages were published
+type PartialError struct {
+	Published int
+	Failed    []error
+}
+
+func (e *PartialError) Error() string {
+	var messages []string
+	for _, err := range e.Failed {
+		messages = append(messages, err.Error())
+	}
+	return fmt.Sprintf("%d item(s) failed: %s", len(e.Failed), strings.Join(messages, "; "))
+}
+
+// Unwrap exposes the failures to errors.Is and errors.As
+func (e *PartialError) Unwrap() []error {
+	return e.Failed
+}
+
+// pageFailure ties the error of a step to the page it failed for
+func pageFailure(step string, page string, err error) error {
+	return fmt.Errorf("%s of %s: %w", step, page, err)
+}
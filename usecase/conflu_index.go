@@ -0,0 +1,85 @@
+package usecase
+
+import (
+	"html"
+	"strings"
+
+	"github.com/arifth/botthie/model"
+)
+
+// confluenceIndex renders the parent page of a document: its description, a
+// table of contents and a table per group linking to the published children.
+// links holds the address of every published child by its index, empty when
+// the response did not carry it
+func confluenceIndex(key string, parent model.DocPage, pages []model.DocPage, links map[int]string) model.DocPage {
+	var groups []string
+	rows := map[string][]int{}
+	for i, page := range pages {
+		if _, ok := rows[page.Group]; !ok {
+			groups = append(groups, page.Group)
+		}
+		rows[page.Group] = append(rows[page.Group], i)
+	}
+
+	var sb strings.Builder
+	content := []model.ADFNode{}
+	if parent.Description != "" {
+		sb.WriteString("<p>" + storageText(parent.Description) + "</p>")
+		content = append(content, adfParagraph(adfTextLines(parent.Description)...))
+	}
+	// ADF has no table of contents outside of extensions
+	sb.WriteString(`<ac:structured-macro ac:name="toc"><ac:parameter ac:name="maxLevel">2</ac:parameter></ac:structured-macro>`)
+	for _, group := range groups {
+		sb.WriteString("<h2>" + html.EscapeString(group) + "</h2>")
+		sb.WriteString("<table><thead><tr><th>Method</th><th>Path</th><th>Name</th></tr></thead><tbody>")
+		var cells [][]string
+		for _, i := range rows[group] {
+			page := pages[i]
+			sb.WriteString("<tr><td>")
+			if page.Method != "" {
+				sb.WriteString(`<ac:structured-macro ac:name="status"><ac:parameter ac:name="colour">` + storageStatusColor(page.Method) +
+					`</ac:parameter><ac:parameter ac:name="title">` + html.EscapeString(page.Method) + `</ac:parameter></ac:structured-macro>`)
+			}
+			sb.WriteString("</td><td>")
+			if path := indexPath(page.URL); path != "" {
+				sb.WriteString("<code>" + html.EscapeString(path) + "</code>")
+			}
+			sb.WriteString("</td><td>")
+			if _, ok := links[i]; ok {
+				// storage format links by title so they survive renames of the
+				// site address
+				sb.WriteString(`<ac:link><ri:page ri:content-title="` + html.EscapeString(childTitle(key, page)) + `"/>` +
+					`<ac:plain-text-link-body>` + storageCDATA(page.Title) + `</ac:plain-text-link-body></ac:link>`)
+			} else {
+				sb.WriteString(html.EscapeString(page.Title))
+			}
+			sb.WriteString("</td></tr>")
+			cells = append(cells, []string{page.Method, indexPath(page.URL), page.Title})
+		}
+		sb.WriteString("</tbody></table>")
+
+		table := adfTable([]string{"Method", "Path", "Name"}, cells)
+		for row, i := range rows[group] {
+			if links[i] != "" {
+				name := adfText(pages[i].Title)
+				name.Marks = []model.ADFMark{{Type: "link", Attrs: map[string]interface{}{"href": links[i]}}}
+				table.Content[row+1].Content[2].Content = []model.ADFNode{adfParagraph(name)}
+			}
+		}
+		content = append(content, adfHeading(2, group), table)
+	}
+	return model.DocPage{Title: parent.Title, HTML: sb.String(), ADF: ADFString(adfDoc(content))}
+}
+
+// indexPath shortens the URL of an endpoint to its path
+func indexPath(url string) string {
+	if _, path := splitPostmanURL(url); path != "" {
+		return path
+	}
+	return url
+}
+
+// storageText escapes text for storage format, keeping its line breaks
+func storageText(text string) string {
+	return strings.ReplaceAll(html.EscapeString(strings.TrimSpace(text)), "\n", "<br/>")
+}
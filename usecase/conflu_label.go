@@ -0,0 +1,79 @@
+package usecase
+
+import (
+	"os"
+	"strings"
+	"time"
+
+	"github.com/arifth/botthie/model"
+)
+
+// defaultLabels is used when CONFLUENCE_LABELS is not set
+const defaultLabels = "collection,method,service"
+
+// pageLabels returns the labels listed in CONFLUENCE_LABELS. The entries
+// collection, method and service stand for the values of the page, every
+// other entry is added as is. Pages without a method or service skip those
+func pageLabels(collection string, service string, method string) []string {
+	entries := os.Getenv("CONFLUENCE_LABELS")
+	if entries == "" {
+		entries = defaultLabels
+	}
+	var labels []string
+	seen := map[string]bool{}
+	for _, entry := range strings.Split(entries, ",") {
+		value := strings.TrimSpace(entry)
+		switch strings.ToLower(value) {
+		case "collection":
+			value = collection
+		case "service":
+			value = service
+		case "method":
+			value = method
+		}
+		// labels are lowercase and cannot contain spaces
+		if value == "" {
+			continue
+		}
+		if label := slugify(value); !seen[label] {
+			seen[label] = true
+			labels = append(labels, label)
+		}
+	}
+	return labels
+}
+
+// pageProperties returns the rows of the page properties macro of an
+// endpoint page, which Page Properties Report macros aggregate across pages.
+// The owner is CONFLUENCE_OWNER
+func pageProperties(doc model.APIDocument, endpoint model.APIEndpoint, generated time.Time) []model.APIAttribute {
+	return []model.APIAttribute{
+		{Key: "Method", Value: endpoint.Method},
+		{Key: "Path", Value: indexPath(endpoint.URL)},
+		{Key: "Owner", Value: os.Getenv("CONFLUENCE_OWNER")},
+		{Key: "Version", Value: doc.Version},
+		{Key: "Last generated", Value: generated.Format("2006-01-02 15:04 MST")},
+	}
+}
+
+// adfDetails renders the page properties as the details macro of Confluence
+// Cloud
+func adfDetails(properties []model.APIAttribute) model.ADFNode {
+	var rows [][]string
+	for _, p := range properties {
+		rows = append(rows, []string{p.Key, p.Value})
+	}
+	table := adfTable([]string{"Property", "Value"}, rows)
+	// the macro reads its keys from the first column
+	table.Content = table.Content[1:]
+	return model.ADFNode{
+		Type: "bodiedExtension",
+		Attrs: map[string]interface{}{
+			"extensionType": "com.atlassian.confluence.macro.core",
+			"extensionKey":  "details",
+			"parameters":    map[string]interface{}{"macroParams": map[string]interface{}{}},
+			"layout":        "default",
+		},
+		Content: []model.ADFNode{table},
+	}
+}
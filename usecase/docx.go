@@ -0,0 +1,434 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/arifth/botthie/model"
+	"github.com/arifth/botthie/util"
+)
+
+// ErrInvalidDOCXTemplate is returned when an uploaded style template is not a
+// Word document
+var ErrInvalidDOCXTemplate = errors.New("not a .docx document with styles")
+
+// docxWidth is the printable width in twips of an A4 page with 2 cm margins
+const docxWidth = 9638
+
+// docxStyles are the styles the renderer relies on. They make up the
+// styles.xml of a plain document and are added to a style template that
+// does not define them
+var docxStyles = []struct {
+	id  string
+	xml string
+}{
+	{"Normal", `<w:style w:type="paragraph" w:default="1" w:styleId="Normal"><w:name w:val="Normal"/><w:qFormat/><w:pPr><w:spacing w:after="120" w:line="264" w:lineRule="auto"/></w:pPr><w:rPr><w:rFonts w:ascii="Calibri" w:hAnsi="Calibri" w:cs="Calibri"/><w:sz w:val="21"/></w:rPr></w:style>`},
+	{"Title", `<w:style w:type="paragraph" w:styleId="Title"><w:name w:val="Title"/><w:basedOn w:val="Normal"/><w:next w:val="Normal"/><w:qFormat/><w:pPr><w:spacing w:before="2400" w:after="240"/><w:jc w:val="center"/></w:pPr><w:rPr><w:b/><w:color w:val="172B4D"/><w:sz w:val="56"/></w:rPr></w:style>`},
+	{"Subtitle", `<w:style w:type="paragraph" w:styleId="Subtitle"><w:name w:val="Subtitle"/><w:basedOn w:val="Normal"/><w:next w:val="Normal"/><w:qFormat/><w:pPr><w:jc w:val="center"/></w:pPr><w:rPr><w:color w:val="5E6C84"/><w:sz w:val="28"/></w:rPr></w:style>`},
+	{"Heading1", `<w:style w:type="paragraph" w:styleId="Heading1"><w:name w:val="heading 1"/><w:basedOn w:val="Normal"/><w:next w:val="Normal"/><w:qFormat/><w:pPr><w:keepNext/><w:pageBreakBefore/><w:spacing w:before="240" w:after="160"/><w:outlineLvl w:val="0"/></w:pPr><w:rPr><w:b/><w:color w:val="0747A6"/><w:sz w:val="36"/></w:rPr></w:style>`},
+	{"Heading2", `<w:style w:type="paragraph" w:styleId="Heading2"><w:name w:val="heading 2"/><w:basedOn w:val="Normal"/><w:next w:val="Normal"/><w:qFormat/><w:pPr><w:keepNext/><w:spacing w:before="360" w:after="120"/><w:outlineLvl w:val="1"/></w:pPr><w:rPr><w:b/><w:color w:val="172B4D"/><w:sz w:val="28"/></w:rPr></w:style>`},
+	{"Heading3", `<w:style w:type="paragraph" w:styleId="Heading3"><w:name w:val="heading 3"/><w:basedOn w:val="Normal"/><w:next w:val="Normal"/><w:qFormat/><w:pPr><w:keepNext/><w:spacing w:before="240" w:after="80"/><w:outlineLvl w:val="2"/></w:pPr><w:rPr><w:b/><w:color w:val="42526E"/><w:sz w:val="24"/></w:rPr></w:style>`},
+	{"TOCHeading", `<w:style w:type="paragraph" w:styleId="TOCHeading"><w:name w:val="TOC Heading"/><w:basedOn w:val="Normal"/><w:next w:val="Normal"/><w:pPr><w:pageBreakBefore/><w:spacing w:after="240"/></w:pPr><w:rPr><w:b/><w:sz w:val="36"/></w:rPr></w:style>`},
+	{"Code", `<w:style w:type="paragraph" w:customStyle="1" w:styleId="Code"><w:name w:val="Code"/><w:basedOn w:val="Normal"/><w:pPr><w:shd w:val="clear" w:color="auto" w:fill="F4F5F7"/><w:spacing w:after="0" w:line="240" w:lineRule="auto"/></w:pPr><w:rPr><w:rFonts w:ascii="Consolas" w:hAnsi="Consolas" w:cs="Consolas"/><w:sz w:val="17"/></w:rPr></w:style>`},
+	{"Endpoint", `<w:style w:type="paragraph" w:customStyle="1" w:styleId="Endpoint"><w:name w:val="Endpoint"/><w:basedOn w:val="Code"/><w:pPr><w:spacing w:before="60" w:after="160"/></w:pPr><w:rPr><w:b/><w:sz w:val="20"/></w:rPr></w:style>`},
+	{"TableHeader", `<w:style w:type="paragraph" w:customStyle="1" w:styleId="TableHeader"><w:name w:val="Table Header"/><w:basedOn w:val="Normal"/><w:pPr><w:spacing w:after="0"/></w:pPr><w:rPr><w:b/><w:sz w:val="18"/></w:rPr></w:style>`},
+	{"TableText", `<w:style w:type="paragraph" w:customStyle="1" w:styleId="TableText"><w:name w:val="Table Text"/><w:basedOn w:val="Normal"/><w:pPr><w:spacing w:after="0" w:line="240" w:lineRule="auto"/></w:pPr><w:rPr><w:sz w:val="18"/></w:rPr></w:style>`},
+	{"TableGrid", `<w:style w:type="table" w:styleId="TableGrid"><w:name w:val="Table Grid"/><w:tblPr><w:tblBorders><w:top w:val="single" w:sz="4" w:space="0" w:color="C1C7D0"/><w:left w:val="single" w:sz="4" w:space="0" w:color="C1C7D0"/><w:bottom w:val="single" w:sz="4" w:space="0" w:color="C1C7D0"/><w:right w:val="single" w:sz="4" w:space="0" w:color="C1C7D0"/><w:insideH w:val="single" w:sz="4" w:space="0" w:color="C1C7D0"/><w:insideV w:val="single" w:sz="4" w:space="0" w:color="C1C7D0"/></w:tblBorders><w:tblCellMar><w:top w:w="40" w:type="dxa"/><w:left w:w="80" w:type="dxa"/><w:bottom w:w="40" w:type="dxa"/><w:right w:w="80" w:type="dxa"/></w:tblCellMar></w:tblPr></w:style>`},
+}
+
+// docxTemplateParts are the parts taken over from a style template
+var docxTemplateParts = []struct {
+	path        string
+	contentType string
+	relType     string
+}{
+	{"word/theme/theme1.xml", "application/vnd.openxmlformats-officedocument.theme+xml", "theme"},
+	{"word/fontTable.xml", "application/vnd.openxmlformats-officedocument.wordprocessingml.fontTable+xml", "fontTable"},
+	{"word/numbering.xml", "application/vnd.openxmlformats-officedocument.wordprocessingml.numbering+xml", "numbering"},
+}
+
+var (
+	xmlInvalidChars = regexp.MustCompile(`[\x00-\x08\x0B\x0C\x0E-\x1F]`)
+	docxStylesEnd   = regexp.MustCompile(`</w:styles>\s*$`)
+)
+
+// docxWriter collects the body of word/document.xml
+type docxWriter struct {
+	sb strings.Builder
+}
+
+// RenderDOCX renders the documents as one Word document with a cover, a table
+// of contents, a Heading 1 chapter per service and real Word tables. When
+// styleTemplate holds a .docx its styles, theme and fonts are used instead of
+// the built-in look
+func (Usecase) RenderDOCX(title string, docs []model.APIDocument, styleTemplate []byte, uc *Usecase) ([]byte, error) {
+	w := &docxWriter{}
+	w.cover(title, docs)
+	for _, doc := range docs {
+		w.document(doc, len(docs) > 1)
+	}
+
+	files := map[string][]byte{
+		"_rels/.rels":       []byte(docxRootRels),
+		"docProps/core.xml": []byte(fmt.Sprintf(docxCoreProps, xmlEscape(title), time.Now().UTC().Format(time.RFC3339))),
+		"word/settings.xml": []byte(docxSettings),
+		"word/document.xml": []byte(docxDocumentStart + w.sb.String() + docxDocumentEnd),
+		"word/styles.xml":   []byte(docxDefaultStyles()),
+	}
+	overrides := ""
+	rels := ""
+	if styleTemplate != nil {
+		parts, err := readDOCXTemplate(styleTemplate)
+		if err != nil {
+			return nil, err
+		}
+		files["word/styles.xml"] = []byte(mergeDOCXStyles(string(parts["word/styles.xml"])))
+		for i, part := range docxTemplateParts {
+			content, ok := parts[part.path]
+			if !ok {
+				continue
+			}
+			files[part.path] = content
+			overrides += fmt.Sprintf(`<Override PartName="/%s" ContentType="%s"/>`, part.path, part.contentType)
+			rels += fmt.Sprintf(`<Relationship Id="rIdT%d" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/%s" Target="%s"/>`,
+				i+1, part.relType, strings.TrimPrefix(part.path, "word/"))
+		}
+	}
+	files["[Content_Types].xml"] = []byte(fmt.Sprintf(docxContentTypes, overrides))
+	files["word/_rels/document.xml.rels"] = []byte(fmt.Sprintf(docxDocumentRels, rels))
+	return util.WriteZip(files)
+}
+
+// IsDOCXTemplate reports whether data is a Word document whose styles can be
+// used as a template
+func IsDOCXTemplate(data []byte) bool {
+	_, err := readDOCXTemplate(data)
+	return err == nil
+}
+
+func readDOCXTemplate(data []byte) (map[string][]byte, error) {
+	parts, err := util.ReadZip(data)
+	if err != nil {
+		return nil, ErrInvalidDOCXTemplate
+	}
+	if _, ok := parts["word/styles.xml"]; !ok {
+		return nil, ErrInvalidDOCXTemplate
+	}
+	return parts, nil
+}
+
+func docxDefaultStyles() string {
+	var sb strings.Builder
+	sb.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>`)
+	sb.WriteString(`<w:styles xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main">`)
+	sb.WriteString(`<w:docDefaults><w:rPrDefault><w:rPr><w:lang w:val="en-US"/></w:rPr></w:rPrDefault></w:docDefaults>`)
+	for _, style := range docxStyles {
+		sb.WriteString(style.xml)
+	}
+	sb.WriteString(`</w:styles>`)
+	return sb.String()
+}
+
+// mergeDOCXStyles adds the styles the renderer relies on to the styles of a
+// template, keeping every style the template already defines
+func mergeDOCXStyles(styles string) string {
+	var missing strings.Builder
+	for _, style := range docxStyles {
+		if !strings.Contains(styles, fmt.Sprintf(`w:styleId="%s"`, style.id)) {
+			missing.WriteString(style.xml)
+		}
+	}
+	return docxStylesEnd.ReplaceAllLiteralString(styles, missing.String()+`</w:styles>`)
+}
+
+func (w *docxWriter) cover(title string, docs []model.APIDocument) {
+	w.paragraph("Title", title)
+	w.paragraph("Subtitle", "API Specification")
+	for _, doc := range docs {
+		line := doc.Name
+		if doc.Format != "" {
+			line += " - " + doc.Format
+		}
+		if doc.Version != "" {
+			line += " " + doc.Version
+		}
+		w.paragraph("Subtitle", line)
+	}
+	if len(docs) == 1 {
+		w.paragraph("Subtitle", docs[0].Description)
+	}
+	w.paragraph("Subtitle", "Generated "+time.Now().Format("2 January 2006"))
+
+	// Word fills the table of contents field when the document is opened
+	w.paragraph("TOCHeading", "Table of Contents")
+	w.sb.WriteString(`<w:p><w:r><w:fldChar w:fldCharType="begin" w:dirty="true"/></w:r>`)
+	w.sb.WriteString(`<w:r><w:instrText xml:space="preserve"> TOC \o "1-3" \h \z \u </w:instrText></w:r>`)
+	w.sb.WriteString(`<w:r><w:fldChar w:fldCharType="separate"/></w:r>`)
+	w.sb.WriteString(`<w:r><w:t>Right-click and choose Update Field to build the table of contents.</w:t></w:r>`)
+	w.sb.WriteString(`<w:r><w:fldChar w:fldCharType="end"/></w:r></w:p>`)
+}
+
+func (w *docxWriter) document(doc model.APIDocument, part bool) {
+	level := 1
+	if part {
+		w.paragraph("Heading1", doc.Name)
+		w.paragraph("", doc.Description)
+		level = 2
+	}
+	if len(doc.Auth) > 0 {
+		if part {
+			w.paragraph("Heading3", "Authentication")
+		} else {
+			w.paragraph("Heading1", "Authentication")
+		}
+		w.authTable(authRows(doc.Auth))
+	}
+
+	for _, service := range doc.Services {
+		w.paragraph(docxHeading(level), firstNonEmpty(service.Name, "Endpoints"))
+		w.paragraph("", service.Description)
+		if service.BaseURL != "" {
+			w.paragraph("", "Base URL: "+service.BaseURL)
+		}
+		for _, endpoint := range service.Endpoints {
+			w.endpoint(level+1, requestData(service, endpoint))
+		}
+	}
+
+	if len(doc.Types) > 0 {
+		w.paragraph(docxHeading(level), "Types")
+		for _, t := range typeRows(doc.Types) {
+			w.paragraph(docxHeading(level+1), fmt.Sprintf("%s %s", t.Kind, t.Name))
+			w.typeBody(t)
+		}
+	}
+}
+
+func (w *docxWriter) endpoint(level int, req model.RequestData) {
+	w.paragraph(docxHeading(level), req.Name)
+	w.paragraph("Endpoint", strings.TrimSpace(req.Method+" "+req.URL))
+	w.paragraph("", req.Description)
+	if req.Deprecated != "" {
+		w.paragraph("", "Deprecated: "+req.Deprecated)
+	}
+	sub := docxHeading(level + 1)
+
+	if len(req.Attributes) > 0 {
+		var rows [][]string
+		for _, a := range req.Attributes {
+			rows = append(rows, []string{a.Key, a.Value})
+		}
+		w.table([]string{"Key", "Value"}, []float64{0.3, 0.7}, rows)
+	}
+	if len(req.Auth) > 0 {
+		w.paragraph(sub, "Authentication")
+		w.authTable(req.Auth)
+	}
+	if len(req.Headers) > 0 {
+		w.paragraph(sub, "Headers")
+		var rows [][]string
+		for _, h := range req.Headers {
+			rows = append(rows, []string{h.Key, h.Value})
+		}
+		w.table([]string{"Key", "Value"}, []float64{0.35, 0.65}, rows)
+	}
+	if len(req.Parameters) > 0 {
+		w.paragraph(sub, "Parameters")
+		var rows [][]string
+		for _, p := range req.Parameters {
+			rows = append(rows, []string{fmt.Sprint(p.Number), p.Name, p.In, p.Type, p.Mandatory, p.Default, p.Description})
+		}
+		w.table([]string{"No.", "Name", "In", "Type", "Mandatory", "Default", "Description"}, []float64{0.06, 0.18, 0.1, 0.13, 0.11, 0.12, 0.3}, rows)
+	}
+	if len(req.BodyFields) > 0 || req.Body != "" {
+		w.paragraph(sub, "Request Body")
+		w.fieldTable(req.BodyFields)
+		w.code(req.Body)
+	}
+	for _, r := range req.Responses {
+		w.paragraph(sub, "Response "+r.Status)
+		w.paragraph("", r.Description)
+		if len(r.Headers) > 0 {
+			var rows [][]string
+			for _, h := range r.Headers {
+				rows = append(rows, []string{fmt.Sprint(h.Number), h.Name, h.Type, h.Description})
+			}
+			w.table([]string{"No.", "Header", "Type", "Description"}, []float64{0.06, 0.3, 0.2, 0.44}, rows)
+		}
+		w.fieldTable(r.BodyFields)
+		w.code(r.Body)
+	}
+	for _, e := range req.Examples {
+		w.paragraph(sub, "Example: "+firstNonEmpty(e.Name, "sample"))
+		if e.Request != "" {
+			w.paragraph("", "Request")
+			w.code(e.Request)
+		}
+		if e.Response != "" {
+			w.paragraph("", strings.TrimSpace("Response "+e.Status))
+			w.code(e.Response)
+		}
+	}
+	for _, t := range req.Types {
+		w.paragraph(sub, fmt.Sprintf("%s %s", t.Kind, t.Name))
+		w.typeBody(t)
+	}
+}
+
+func (w *docxWriter) typeBody(t model.TypeData) {
+	w.paragraph("", t.Description)
+	w.fieldTable(t.Fields)
+	if len(t.Values) > 0 {
+		var rows [][]string
+		for _, v := range t.Values {
+			rows = append(rows, []string{fmt.Sprint(v.Number), v.Field, v.Description})
+		}
+		w.table([]string{"No.", "Value", "Description"}, []float64{0.06, 0.34, 0.6}, rows)
+	}
+}
+
+func (w *docxWriter) fieldTable(fields []model.BodyField) {
+	if len(fields) == 0 {
+		return
+	}
+	var rows [][]string
+	for _, f := range fields {
+		rows = append(rows, []string{fmt.Sprint(f.Number), f.Field, f.Type, f.Mandatory, f.Description})
+	}
+	w.table([]string{"No.", "Field", "Type", "Mandatory", "Description"}, []float64{0.06, 0.26, 0.16, 0.12, 0.4}, rows)
+}
+
+func (w *docxWriter) authTable(auth []model.AuthData) {
+	var rows [][]string
+	for _, a := range auth {
+		rows = append(rows, []string{a.Name, a.Type, a.Location, a.Scopes, a.Description})
+	}
+	w.table([]string{"Name", "Type", "Location", "Scopes", "Description"}, []float64{0.16, 0.14, 0.22, 0.16, 0.32}, rows)
+}
+
+// paragraph writes a paragraph in a style, skipping empty text
+func (w *docxWriter) paragraph(style string, text string) {
+	if text == "" {
+		return
+	}
+	w.sb.WriteString("<w:p>")
+	if style != "" {
+		w.sb.WriteString(fmt.Sprintf(`<w:pPr><w:pStyle w:val="%s"/></w:pPr>`, style))
+	}
+	w.sb.WriteString(docxRun(text))
+	w.sb.WriteString("</w:p>")
+}
+
+// table writes a Word table whose header row repeats on every page. widths
+// are fractions of the printable width
+func (w *docxWriter) table(header []string, widths []float64, rows [][]string) {
+	cols := make([]int, len(widths))
+	for i, f := range widths {
+		cols[i] = int(f * docxWidth)
+	}
+	w.sb.WriteString(`<w:tbl><w:tblPr><w:tblStyle w:val="TableGrid"/><w:tblW w:w="5000" w:type="pct"/><w:tblLook w:val="04A0" w:firstRow="1" w:lastRow="0" w:firstColumn="0" w:lastColumn="0" w:noHBand="1" w:noVBand="1"/></w:tblPr><w:tblGrid>`)
+	for _, col := range cols {
+		w.sb.WriteString(fmt.Sprintf(`<w:gridCol w:w="%d"/>`, col))
+	}
+	w.sb.WriteString(`</w:tblGrid>`)
+	writeRow := func(cells []string, head bool) {
+		w.sb.WriteString("<w:tr>")
+		if head {
+			w.sb.WriteString(`<w:trPr><w:tblHeader/></w:trPr>`)
+		}
+		for i, cell := range cells {
+			w.sb.WriteString(fmt.Sprintf(`<w:tc><w:tcPr><w:tcW w:w="%d" w:type="dxa"/>`, cols[i]))
+			style := "TableText"
+			if head {
+				style = "TableHeader"
+				w.sb.WriteString(`<w:shd w:val="clear" w:color="auto" w:fill="EBECF0"/>`)
+			}
+			w.sb.WriteString(fmt.Sprintf(`</w:tcPr><w:p><w:pPr><w:pStyle w:val="%s"/></w:pPr>%s</w:p></w:tc>`, style, docxRun(cell)))
+		}
+		w.sb.WriteString("</w:tr>")
+	}
+	writeRow(header, true)
+	for _, row := range rows {
+		writeRow(row, false)
+	}
+	// Word needs a paragraph between two tables
+	w.sb.WriteString(`</w:tbl><w:p/>`)
+}
+
+// code writes a body as one paragraph per line in the Code style
+func (w *docxWriter) code(body string) {
+	body = strings.TrimSpace(body)
+	if body == "" {
+		return
+	}
+	for _, line := range strings.Split(strings.ReplaceAll(body, "\r\n", "\n"), "\n") {
+		w.sb.WriteString(`<w:p><w:pPr><w:pStyle w:val="Code"/></w:pPr>`)
+		if line != "" {
+			w.sb.WriteString(docxRun(strings.ReplaceAll(line, "\t", "    ")))
+		}
+		w.sb.WriteString("</w:p>")
+	}
+	w.sb.WriteString("<w:p/>")
+}
+
+// docxHeading returns the heading style of a level, Word only ships three
+// levels with the look of a heading in plain documents
+func docxHeading(level int) string {
+	if level > 3 {
+		level = 3
+	}
+	return fmt.Sprintf("Heading%d", level)
+}
+
+// docxRun writes text as a run, keeping line breaks and spaces
+func docxRun(text string) string {
+	lines := strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+	for i, line := range lines {
+		lines[i] = fmt.Sprintf(`<w:t xml:space="preserve">%s</w:t>`, xmlEscape(line))
+	}
+	return "<w:r>" + strings.Join(lines, "<w:br/>") + "</w:r>"
+}
+
+func xmlEscape(s string) string {
+	s = xmlInvalidChars.ReplaceAllString(s, "")
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", `"`, "&quot;").Replace(s)
+}
+
+// SendDOCX renders the documents as one Word document and sends it back to
+// the chat as a document named after name
+func (Usecase) SendDOCX(name string, docs []model.APIDocument, styleTemplate []byte, uc *Usecase) error {
+	data, err := uc.RenderDOCX(name, docs, styleTemplate, uc)
+	if err != nil {
+		return err
+	}
+	caption := fmt.Sprintf("📘 Word specification of %s", name)
+	if styleTemplate != nil {
+		caption += " in the uploaded style"
+	}
+	return uc.SendFile(slugify(name)+".docx", data, caption, uc)
+}
+
+const docxContentTypes = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types"><Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/><Default Extension="xml" ContentType="application/xml"/><Override PartName="/word/document.xml" ContentType="application/vnd.openxmlformats-officedocument.wordprocessingml.document.main+xml"/><Override PartName="/word/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.wordprocessingml.styles+xml"/><Override PartName="/word/settings.xml" ContentType="application/vnd.openxmlformats-officedocument.wordprocessingml.settings+xml"/><Override PartName="/docProps/core.xml" ContentType="application/vnd.openxmlformats-package.core-properties+xml"/>%s</Types>`
+
+const docxRootRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="word/document.xml"/><Relationship Id="rId2" Type="http://schemas.openxmlformats.org/package/2006/relationships/metadata/core-properties" Target="docProps/core.xml"/></Relationships>`
+
+const docxDocumentRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/><Relationship Id="rId2" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/settings" Target="settings.xml"/>%s</Relationships>`
+
+const docxCoreProps = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<cp:coreProperties xmlns:cp="http://schemas.openxmlformats.org/package/2006/metadata/core-properties" xmlns:dc="http://purl.org/dc/elements/1.1/" xmlns:dcterms="http://purl.org/dc/terms/" xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance"><dc:title>%s</dc:title><dcterms:created xsi:type="dcterms:W3CDTF">%s</dcterms:created></cp:coreProperties>`
+
+// docxSettings asks Word to refresh the table of contents on opening
+const docxSettings = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<w:settings xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"><w:updateFields w:val="true"/></w:settings>`
+
+const docxDocumentStart = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"><w:body>`
+
+// docxDocumentEnd closes the body with an A4 section with 2 cm margins
+const docxDocumentEnd = `<w:sectPr><w:pgSz w:w="11906" w:h="16838"/><w:pgMar w:top="1134" w:right="1134" w:bottom="1134" w:left="1134" w:header="708" w:footer="708" w:gutter="0"/></w:sectPr></w:body></w:document>`
@@ -0,0 +1,146 @@
+package usecase
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/arifth/botthie/model"
+)
+
+// GitPublisher writes the documents as Markdown into the working tree of a
+// local repository at Repo, below Dir, commits them and pushes the commit to
+// Remote when it is set. Branch names the remote branch, the current branch
+// by default
+type GitPublisher struct {
+	Repo   string
+	Dir    string
+	Remote string
+	Branch string
+	UC     *Usecase
+}
+
+// NewGitPublisher returns a GitPublisher configured with GIT_REPO_DIR,
+// GIT_DOCS_DIR, GIT_REMOTE and GIT_BRANCH
+func NewGitPublisher(uc *Usecase) GitPublisher {
+	return GitPublisher{
+		Repo:   os.Getenv("GIT_REPO_DIR"),
+		Dir:    os.Getenv("GIT_DOCS_DIR"),
+		Remote: os.Getenv("GIT_REMOTE"),
+		Branch: os.Getenv("GIT_BRANCH"),
+		UC:     uc,
+	}
+}
+
+func (GitPublisher) Name() string { return "Git" }
+
+func (p GitPublisher) Publish(name string, docs []model.APIDocument) model.PublishResult {
+	if p.Repo == "" {
+		return model.PublishResult{Err: errors.New("GIT_REPO_DIR is not set")}
+	}
+	writeMu.Lock()
+	defer writeMu.Unlock()
+
+	rel := path.Join(filepath.ToSlash(p.Dir), slugify(name))
+	files := p.UC.MarkdownFiles(docs, p.UC)
+	if err := writeFiles(filepath.Join(p.Repo, filepath.FromSlash(rel)), files); err != nil {
+		return model.PublishResult{Err: err}
+	}
+	if _, err := p.git("add", "--all", "--", rel); err != nil {
+		return model.PublishResult{Err: err}
+	}
+	changes, err := p.git("diff", "--cached", "--name-status", "--no-renames", "-z", "--", rel)
+	if err != nil {
+		return model.PublishResult{Err: err}
+	}
+	res := model.PublishResult{Pages: len(files), Location: p.Repo}
+	if changes == "" {
+		// the documentation is unchanged since the last job
+		return res
+	}
+
+	message := gitCommitMessage(name, rel, files, changes)
+	if _, err := p.git("commit", "--quiet", "--message", message, "--", rel); err != nil {
+		return model.PublishResult{Err: err}
+	}
+	if commit, err := p.git("rev-parse", "--short", "HEAD"); err == nil {
+		res.Location = fmt.Sprintf("%s@%s", p.Repo, strings.TrimSpace(commit))
+	}
+	if p.Remote != "" {
+		ref := "HEAD"
+		if p.Branch != "" {
+			ref = "HEAD:refs/heads/" + p.Branch
+		}
+		if _, err := p.git("push", "--quiet", p.Remote, ref); err != nil {
+			res.Err = fmt.Errorf("committed but failed to push: %w", err)
+		}
+	}
+	return res
+}
+
+// git runs a git command in the repository and returns its output
+func (p GitPublisher) git(args ...string) (string, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = p.Repo
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("git %s: %v %s", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+	return stdout.String(), nil
+}
+
+// gitCommitMessage describes the collection and lists the pages added,
+// updated and removed, using the heading of each page as its name. changes
+// is the NUL separated output of git diff --name-status -z
+func gitCommitMessage(name string, rel string, files map[string][]byte, changes string) string {
+	groups := map[string][]string{}
+	fields := strings.Split(strings.TrimSuffix(changes, "\x00"), "\x00")
+	for i := 0; i+1 < len(fields); i += 2 {
+		status, file := fields[i], strings.TrimPrefix(fields[i+1], rel+"/")
+		if path.Base(file) == "README.md" {
+			continue
+		}
+		title := markdownTitle(files[file], file)
+		switch status {
+		case "A":
+			groups["Added"] = append(groups["Added"], title)
+		case "D":
+			groups["Removed"] = append(groups["Removed"], title)
+		default:
+			groups["Updated"] = append(groups["Updated"], title)
+		}
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Update API documentation of %s\n", name))
+	for _, group := range []string{"Added", "Updated", "Removed"} {
+		titles := groups[group]
+		if len(titles) == 0 {
+			continue
+		}
+		sort.Strings(titles)
+		sb.WriteString(fmt.Sprintf("\n%s:\n", group))
+		for _, title := range titles {
+			sb.WriteString("- " + title + "\n")
+		}
+	}
+	return sb.String()
+}
+
+// markdownTitle returns the first heading of a rendered page, or its path
+// when the page was removed
+func markdownTitle(content []byte, file string) string {
+	line, _, _ := strings.Cut(string(content), "\n")
+	if title := strings.TrimPrefix(line, "# "); title != line && title != "" {
+		return fmt.Sprintf("%s (%s)", title, file)
+	}
+	return file
+}
@@ -0,0 +1,91 @@
+package usecase
+
+import (
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/arifth/botthie/model"
+)
+
+// runGit runs git in dir and fails the test on error
+func runGit(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %s: %v %s", strings.Join(args, " "), err, out)
+	}
+	return string(out)
+}
+
+func TestGitPublisherPushesToBareRepo(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	t.Setenv("GIT_AUTHOR_NAME", "Docs Bot")
+	t.Setenv("GIT_AUTHOR_EMAIL", "docs@example.com")
+	t.Setenv("GIT_COMMITTER_NAME", "Docs Bot")
+	t.Setenv("GIT_COMMITTER_EMAIL", "docs@example.com")
+
+	tmp := t.TempDir()
+	remote := filepath.Join(tmp, "remote.git")
+	repo := filepath.Join(tmp, "work")
+	runGit(t, tmp, "init", "--quiet", "--bare", remote)
+	runGit(t, tmp, "init", "--quiet", repo)
+	runGit(t, repo, "remote", "add", "origin", remote)
+
+	p := GitPublisher{Repo: repo, Dir: "docs", Remote: "origin", Branch: "main", UC: &Usecase{}}
+	doc := model.APIDocument{
+		Name: "Pet Store",
+		Services: []model.APIService{{
+			Name: "Pets",
+			Endpoints: []model.APIEndpoint{
+				{Name: "List pets", Method: "GET", URL: "https://api.example.com/pets"},
+			},
+		}},
+	}
+
+	first := p.Publish(doc.Name, []model.APIDocument{doc})
+	if first.Err != nil {
+		t.Fatalf("first publish: %v", first.Err)
+	}
+	message := runGit(t, remote, "log", "-1", "--format=%B", "main")
+	if !strings.Contains(message, "Update API documentation of Pet Store") || !strings.Contains(message, "List pets") {
+		t.Errorf("first commit message does not name the collection and endpoint:\n%s", message)
+	}
+
+	doc.Services[0].Endpoints = append(doc.Services[0].Endpoints,
+		model.APIEndpoint{Name: "Create pet", Method: "POST", URL: "https://api.example.com/pets"})
+	second := p.Publish(doc.Name, []model.APIDocument{doc})
+	if second.Err != nil {
+		t.Fatalf("second publish: %v", second.Err)
+	}
+	message = runGit(t, remote, "log", "-1", "--format=%B", "main")
+	if !strings.Contains(message, "Pet Store") || !strings.Contains(message, "Added:\n- Create pet") || strings.Contains(message, "List pets") {
+		t.Errorf("second commit message does not list the added endpoint only:\n%s", message)
+	}
+
+	if count := strings.TrimSpace(runGit(t, remote, "rev-list", "--count", "main")); count != "2" {
+		t.Errorf("bare repo has %s commit(s) on main, want 2", count)
+	}
+	local := strings.TrimSpace(runGit(t, repo, "rev-parse", "HEAD"))
+	pushed := strings.TrimSpace(runGit(t, remote, "rev-parse", "main"))
+	if local != pushed {
+		t.Errorf("pushed commit %s, want %s", pushed, local)
+	}
+	files := runGit(t, remote, "ls-tree", "-r", "--name-only", "main")
+	if !strings.Contains(files, "docs/pet-store/") {
+		t.Errorf("pushed tree lacks the documentation:\n%s", files)
+	}
+
+	// publishing unchanged documentation creates no commit
+	if third := p.Publish(doc.Name, []model.APIDocument{doc}); third.Err != nil {
+		t.Fatalf("third publish: %v", third.Err)
+	}
+	if count := strings.TrimSpace(runGit(t, remote, "rev-list", "--count", "main")); count != "2" {
+		t.Errorf("unchanged publish pushed a commit, %s commit(s) on main", count)
+	}
+}
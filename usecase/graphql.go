@@ -0,0 +1,454 @@
+package usecase
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/arifth/botthie/model"
+	"github.com/vektah/gqlparser/v2"
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+// introspectionTypeRef is a (possibly wrapped) type reference in an
+// introspection result
+type introspectionTypeRef struct {
+	Kind   string                `json:"kind"`
+	Name   string                `json:"name"`
+	OfType *introspectionTypeRef `json:"ofType"`
+}
+
+type introspectionInputValue struct {
+	Name         string               `json:"name"`
+	Description  string               `json:"description"`
+	Type         introspectionTypeRef `json:"type"`
+	DefaultValue *string              `json:"defaultValue"`
+}
+
+type introspectionField struct {
+	Name              string                    `json:"name"`
+	Description       string                    `json:"description"`
+	Args              []introspectionInputValue `json:"args"`
+	Type              introspectionTypeRef      `json:"type"`
+	IsDeprecated      bool                      `json:"isDeprecated"`
+	DeprecationReason string                    `json:"deprecationReason"`
+}
+
+type introspectionType struct {
+	Kind          string                    `json:"kind"`
+	Name          string                    `json:"name"`
+	Description   string                    `json:"description"`
+	Fields        []introspectionField      `json:"fields"`
+	InputFields   []introspectionInputValue `json:"inputFields"`
+	Interfaces    []introspectionTypeRef    `json:"interfaces"`
+	EnumValues    []introspectionField      `json:"enumValues"`
+	PossibleTypes []introspectionTypeRef    `json:"possibleTypes"`
+}
+
+type introspectionSchema struct {
+	QueryType        *introspectionTypeRef `json:"queryType"`
+	MutationType     *introspectionTypeRef `json:"mutationType"`
+	SubscriptionType *introspectionTypeRef `json:"subscriptionType"`
+	Types            []introspectionType   `json:"types"`
+}
+
+// introspectionResult accepts both the raw `{"data": {"__schema": ...}}`
+// response and the bare `{"__schema": ...}` object
+type introspectionResult struct {
+	Schema *introspectionSchema `json:"__schema"`
+	Data   *struct {
+		Schema *introspectionSchema `json:"__schema"`
+	} `json:"data"`
+}
+
+// IsGraphQLIntrospection reports whether data is a GraphQL introspection
+// query result
+func IsGraphQLIntrospection(data []byte) bool {
+	return introspectionFrom(data) != nil
+}
+
+func introspectionFrom(data []byte) *introspectionSchema {
+	var res introspectionResult
+	if err := json.Unmarshal(data, &res); err != nil {
+		return nil
+	}
+	if res.Schema != nil {
+		return res.Schema
+	}
+	if res.Data != nil {
+		return res.Data.Schema
+	}
+	return nil
+}
+
+// ParseGraphQL builds the documentation model from an SDL document or an
+// introspection JSON result
+func ParseGraphQL(name string, data []byte) (model.GraphQLSchema, error) {
+	sdl := string(data)
+	if schema := introspectionFrom(data); schema != nil {
+		sdl = introspectionToSDL(schema)
+	}
+
+	parsed, err := gqlparser.LoadSchema(&ast.Source{Name: name, Input: sdl})
+	if err != nil {
+		return model.GraphQLSchema{}, fmt.Errorf("invalid GraphQL schema: %w", err)
+	}
+	return buildGraphQLSchema(name, parsed), nil
+}
+
+func introspectionToSDL(schema *introspectionSchema) string {
+	var sb strings.Builder
+
+	roots := []struct {
+		op  string
+		ref *introspectionTypeRef
+		def string
+	}{
+		{"query", schema.QueryType, "Query"},
+		{"mutation", schema.MutationType, "Mutation"},
+		{"subscription", schema.SubscriptionType, "Subscription"},
+	}
+	var rootDefs []string
+	custom := false
+	for _, root := range roots {
+		if root.ref == nil || root.ref.Name == "" {
+			continue
+		}
+		rootDefs = append(rootDefs, fmt.Sprintf("  %s: %s", root.op, root.ref.Name))
+		if root.ref.Name != root.def {
+			custom = true
+		}
+	}
+	if custom {
+		sb.WriteString("schema {\n" + strings.Join(rootDefs, "\n") + "\n}\n\n")
+	}
+
+	for _, t := range schema.Types {
+		if strings.HasPrefix(t.Name, "__") || isBuiltinScalar(t.Name) {
+			continue
+		}
+		writeSDLDescription(&sb, t.Description, "")
+		switch t.Kind {
+		case "SCALAR":
+			sb.WriteString("scalar " + t.Name + "\n\n")
+		case "OBJECT", "INTERFACE":
+			keyword := "type"
+			if t.Kind == "INTERFACE" {
+				keyword = "interface"
+			}
+			sb.WriteString(keyword + " " + t.Name)
+			if len(t.Interfaces) > 0 {
+				var names []string
+				for _, i := range t.Interfaces {
+					names = append(names, i.Name)
+				}
+				sb.WriteString(" implements " + strings.Join(names, " & "))
+			}
+			sb.WriteString(" {\n")
+			for _, f := range t.Fields {
+				writeSDLDescription(&sb, f.Description, "  ")
+				sb.WriteString("  " + f.Name)
+				if len(f.Args) > 0 {
+					var args []string
+					for _, a := range f.Args {
+						args = append(args, inputValueToSDL(a))
+					}
+					sb.WriteString("(" + strings.Join(args, ", ") + ")")
+				}
+				sb.WriteString(": " + typeRefToSDL(f.Type))
+				sb.WriteString(deprecatedToSDL(f.IsDeprecated, f.DeprecationReason) + "\n")
+			}
+			sb.WriteString("}\n\n")
+		case "INPUT_OBJECT":
+			sb.WriteString("input " + t.Name + " {\n")
+			for _, f := range t.InputFields {
+				writeSDLDescription(&sb, f.Description, "  ")
+				sb.WriteString("  " + inputValueToSDL(f) + "\n")
+			}
+			sb.WriteString("}\n\n")
+		case "ENUM":
+			sb.WriteString("enum " + t.Name + " {\n")
+			for _, v := range t.EnumValues {
+				writeSDLDescription(&sb, v.Description, "  ")
+				sb.WriteString("  " + v.Name + deprecatedToSDL(v.IsDeprecated, v.DeprecationReason) + "\n")
+			}
+			sb.WriteString("}\n\n")
+		case "UNION":
+			var names []string
+			for _, p := range t.PossibleTypes {
+				names = append(names, p.Name)
+			}
+			sb.WriteString("union " + t.Name + " = " + strings.Join(names, " | ") + "\n\n")
+		}
+	}
+	return sb.String()
+}
+
+func writeSDLDescription(sb *strings.Builder, description string, indent string) {
+	if description == "" {
+		return
+	}
+	escaped := strings.ReplaceAll(description, `"""`, `\"""`)
+	sb.WriteString(indent + `"""` + escaped + `"""` + "\n")
+}
+
+func inputValueToSDL(v introspectionInputValue) string {
+	s := v.Name + ": " + typeRefToSDL(v.Type)
+	if v.DefaultValue != nil {
+		s += " = " + *v.DefaultValue
+	}
+	return s
+}
+
+func typeRefToSDL(ref introspectionTypeRef) string {
+	switch ref.Kind {
+	case "NON_NULL":
+		if ref.OfType != nil {
+			return typeRefToSDL(*ref.OfType) + "!"
+		}
+	case "LIST":
+		if ref.OfType != nil {
+			return "[" + typeRefToSDL(*ref.OfType) + "]"
+		}
+	}
+	return ref.Name
+}
+
+func deprecatedToSDL(deprecated bool, reason string) string {
+	if !deprecated {
+		return ""
+	}
+	if reason == "" {
+		return " @deprecated"
+	}
+	quoted, _ := json.Marshal(reason)
+	return " @deprecated(reason: " + string(quoted) + ")"
+}
+
+func isBuiltinScalar(name string) bool {
+	switch name {
+	case "String", "Int", "Float", "Boolean", "ID":
+		return true
+	}
+	return false
+}
+
+func buildGraphQLSchema(name string, schema *ast.Schema) model.GraphQLSchema {
+	doc := model.GraphQLSchema{Name: name}
+
+	roots := []struct {
+		kind string
+		def  *ast.Definition
+	}{
+		{"query", schema.Query},
+		{"mutation", schema.Mutation},
+		{"subscription", schema.Subscription},
+	}
+	rootNames := map[string]bool{}
+	for _, root := range roots {
+		if root.def == nil {
+			continue
+		}
+		rootNames[root.def.Name] = true
+		for _, field := range root.def.Fields {
+			if strings.HasPrefix(field.Name, "__") {
+				continue
+			}
+			op := model.GraphQLOperation{
+				Kind:        root.kind,
+				Name:        field.Name,
+				Description: field.Description,
+				Deprecated:  deprecationReason(field.Directives),
+				Arguments:   graphQLArgumentRows(field.Arguments),
+				ReturnType:  field.Type.String(),
+			}
+			if ret := schema.Types[field.Type.Name()]; ret != nil {
+				op.ReturnFields = graphQLFieldRows(ret.Fields)
+			}
+			doc.Operations = append(doc.Operations, op)
+		}
+	}
+
+	var names []string
+	for typeName, def := range schema.Types {
+		if def.BuiltIn || rootNames[typeName] || strings.HasPrefix(typeName, "__") {
+			continue
+		}
+		names = append(names, typeName)
+	}
+	sort.Strings(names)
+
+	for _, typeName := range names {
+		def := schema.Types[typeName]
+		t := model.GraphQLType{
+			Kind:          string(def.Kind),
+			Name:          def.Name,
+			Description:   def.Description,
+			Interfaces:    def.Interfaces,
+			PossibleTypes: def.Types,
+			Fields:        graphQLFieldRows(def.Fields),
+		}
+		for idx, v := range def.EnumValues {
+			t.EnumValues = append(t.EnumValues, model.GraphQLField{
+				Number:      idx + 1,
+				Name:        v.Name,
+				Description: v.Description,
+				Deprecated:  deprecationReason(v.Directives),
+			})
+		}
+		doc.Types = append(doc.Types, t)
+	}
+	return doc
+}
+
+func graphQLArgumentRows(args ast.ArgumentDefinitionList) []model.GraphQLField {
+	var rows []model.GraphQLField
+	for idx, arg := range args {
+		row := model.GraphQLField{
+			Number:      idx + 1,
+			Name:        arg.Name,
+			Type:        arg.Type.String(),
+			Mandatory:   mandatory(arg.Type),
+			Description: arg.Description,
+			Deprecated:  deprecationReason(arg.Directives),
+		}
+		if arg.DefaultValue != nil {
+			row.DefaultValue = arg.DefaultValue.String()
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+func graphQLFieldRows(fields ast.FieldList) []model.GraphQLField {
+	var rows []model.GraphQLField
+	for _, field := range fields {
+		if strings.HasPrefix(field.Name, "__") {
+			continue
+		}
+		row := model.GraphQLField{
+			Number:      len(rows) + 1,
+			Name:        field.Name,
+			Type:        field.Type.String(),
+			Mandatory:   mandatory(field.Type),
+			Description: field.Description,
+			Deprecated:  deprecationReason(field.Directives),
+		}
+		if field.DefaultValue != nil {
+			row.DefaultValue = field.DefaultValue.String()
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+func mandatory(t *ast.Type) string {
+	if t.NonNull {
+		return "Yes"
+	}
+	return "No"
+}
+
+func deprecationReason(directives ast.DirectiveList) string {
+	dep := directives.ForName("deprecated")
+	if dep == nil {
+		return ""
+	}
+	if reason := dep.Arguments.ForName("reason"); reason != nil && reason.Value != nil {
+		return reason.Value.Raw
+	}
+	return "No longer supported"
+}
+
+type graphQLImporter struct{}
+
+func (graphQLImporter) Name() string { return "GraphQL schema" }
+
+func (graphQLImporter) Detect(fileName string, data []byte) bool {
+	if hasExt(fileName, ".graphql", ".graphqls", ".gql") {
+		return true
+	}
+	return hasExt(fileName, ".json") && IsGraphQLIntrospection(data)
+}
+
+func (graphQLImporter) Import(fileName string, data []byte, related map[string][]byte) (model.APIDocument, error) {
+	schema, err := ParseGraphQL(fileName, data)
+	if err != nil {
+		return model.APIDocument{}, err
+	}
+	return graphQLToDocument(schema), nil
+}
+
+// graphQLToDocument maps every root field to an endpoint, its arguments to
+// parameters and its return type fields to the response body
+func graphQLToDocument(schema model.GraphQLSchema) model.APIDocument {
+	doc := model.APIDocument{
+		Name:   schema.Name,
+		Format: graphQLImporter{}.Name(),
+	}
+	for _, op := range schema.Operations {
+		kind := strings.ToUpper(op.Kind[:1]) + op.Kind[1:]
+		endpoint := model.APIEndpoint{
+			Name:        op.Name,
+			Kind:        "GraphQL " + op.Kind,
+			Method:      strings.ToUpper(op.Kind),
+			Description: op.Description,
+			Deprecated:  op.Deprecated,
+			Attributes:  []model.APIAttribute{{Key: "Return type", Value: op.ReturnType}},
+		}
+		for _, arg := range op.Arguments {
+			endpoint.Parameters = append(endpoint.Parameters, model.APIParameter{
+				Name:        arg.Name,
+				In:          "argument",
+				Type:        arg.Type,
+				Required:    arg.Mandatory == "Yes",
+				Default:     arg.DefaultValue,
+				Description: graphQLDescription(arg),
+			})
+		}
+		endpoint.Responses = []model.APIResponse{{
+			Status: op.ReturnType,
+			Body:   &model.APIBody{Fields: graphQLFields(op.ReturnFields)},
+		}}
+		addEndpoint(&doc, kind, endpoint)
+	}
+	for _, t := range schema.Types {
+		description := t.Description
+		if len(t.Interfaces) > 0 {
+			description = strings.TrimSpace(fmt.Sprintf("%s (implements %s)", description, strings.Join(t.Interfaces, ", ")))
+		}
+		if len(t.PossibleTypes) > 0 {
+			description = strings.TrimSpace(fmt.Sprintf("%s (one of %s)", description, strings.Join(t.PossibleTypes, ", ")))
+		}
+		doc.Types = append(doc.Types, model.APIType{
+			Name:        t.Name,
+			Kind:        t.Kind,
+			Description: description,
+			Fields:      graphQLFields(t.Fields),
+			Values:      graphQLFields(t.EnumValues),
+		})
+	}
+	return doc
+}
+
+func graphQLFields(rows []model.GraphQLField) []model.APIField {
+	var fields []model.APIField
+	for _, row := range rows {
+		fields = append(fields, model.APIField{
+			Name:        row.Name,
+			Type:        row.Type,
+			Required:    row.Mandatory == "Yes",
+			Default:     row.DefaultValue,
+			Description: graphQLDescription(row),
+		})
+	}
+	return fields
+}
+
+func graphQLDescription(row model.GraphQLField) string {
+	if row.Deprecated == "" {
+		return row.Description
+	}
+	return strings.TrimSpace(fmt.Sprintf("%s (deprecated: %s)", row.Description, row.Deprecated))
+}
@@ -0,0 +1,372 @@
+package usecase
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/arifth/botthie/model"
+	protoparser "github.com/emicklei/proto"
+)
+
+// protoDefs indexes every message and enum of a set of .proto files by its
+// fully qualified name
+type protoDefs struct {
+	messages map[string]*protoparser.Message
+	enums    map[string]*protoparser.Enum
+}
+
+// ParseProtoFiles builds the documentation model of the services declared in
+// the main file, resolving message and enum references across all .proto
+// files keyed by their path
+func ParseProtoFiles(main string, files map[string][]byte) (model.ProtoAPI, error) {
+	api := model.ProtoAPI{Name: main}
+	defs := protoDefs{
+		messages: map[string]*protoparser.Message{},
+		enums:    map[string]*protoparser.Enum{},
+	}
+
+	var paths []string
+	for path := range files {
+		if strings.HasSuffix(strings.ToLower(path), ".proto") {
+			paths = append(paths, path)
+		}
+	}
+	if len(paths) == 0 {
+		return api, fmt.Errorf("no .proto files found")
+	}
+	sort.Strings(paths)
+
+	var mainFile *protoparser.Proto
+	for _, path := range paths {
+		parsed, err := protoparser.NewParser(bytes.NewReader(files[path])).Parse()
+		if err != nil {
+			return api, fmt.Errorf("%s: %w", path, err)
+		}
+		defs.collect(protoPackage(parsed), parsed.Elements)
+		if path == main {
+			mainFile = parsed
+		}
+	}
+	if mainFile == nil {
+		return api, fmt.Errorf("%s is not a .proto file", main)
+	}
+
+	pkg := protoPackage(mainFile)
+	for _, element := range mainFile.Elements {
+		svc, ok := element.(*protoparser.Service)
+		if !ok {
+			continue
+		}
+		service := model.ProtoService{
+			Name:        svc.Name,
+			Package:     pkg,
+			Description: commentText(svc.Comment),
+		}
+		for _, el := range svc.Elements {
+			rpc, ok := el.(*protoparser.RPC)
+			if !ok {
+				continue
+			}
+			service.RPCs = append(service.RPCs, defs.buildRPC(pkg, svc.Name, rpc))
+		}
+		api.Services = append(api.Services, service)
+	}
+	if len(api.Services) == 0 {
+		return api, fmt.Errorf("no gRPC services found: %w", ErrNothingToDocument)
+	}
+	return api, nil
+}
+
+func protoPackage(parsed *protoparser.Proto) string {
+	for _, element := range parsed.Elements {
+		if pkg, ok := element.(*protoparser.Package); ok {
+			return pkg.Name
+		}
+	}
+	return ""
+}
+
+func (d protoDefs) collect(scope string, elements []protoparser.Visitee) {
+	for _, element := range elements {
+		switch v := element.(type) {
+		case *protoparser.Message:
+			if v.IsExtend {
+				continue
+			}
+			fullName := joinProtoName(scope, v.Name)
+			d.messages[fullName] = v
+			d.collect(fullName, v.Elements)
+		case *protoparser.Enum:
+			d.enums[joinProtoName(scope, v.Name)] = v
+		}
+	}
+}
+
+// resolve applies protobuf scoping rules: a relative name is looked up in the
+// innermost scope first and then in every enclosing scope
+func (d protoDefs) resolve(scope string, typeName string) string {
+	if strings.HasPrefix(typeName, ".") {
+		return strings.TrimPrefix(typeName, ".")
+	}
+	for {
+		candidate := joinProtoName(scope, typeName)
+		if _, ok := d.messages[candidate]; ok {
+			return candidate
+		}
+		if _, ok := d.enums[candidate]; ok {
+			return candidate
+		}
+		if scope == "" {
+			return typeName
+		}
+		if idx := strings.LastIndex(scope, "."); idx >= 0 {
+			scope = scope[:idx]
+		} else {
+			scope = ""
+		}
+	}
+}
+
+func (d protoDefs) buildRPC(pkg string, service string, rpc *protoparser.RPC) model.ProtoRPC {
+	res := model.ProtoRPC{
+		Service:      service,
+		Name:         rpc.Name,
+		Description:  commentText(rpc.Comment),
+		Streaming:    streamingMode(rpc.StreamsRequest, rpc.StreamsReturns),
+		RequestType:  rpc.RequestType,
+		ResponseType: rpc.ReturnsType,
+	}
+
+	// walk every message reachable from the request and response
+	reqName, resName := d.resolve(pkg, rpc.RequestType), d.resolve(pkg, rpc.ReturnsType)
+	queue := []string{reqName, resName}
+	seen := map[string]bool{}
+	for len(queue) > 0 {
+		fullName := queue[0]
+		queue = queue[1:]
+		if seen[fullName] {
+			continue
+		}
+		seen[fullName] = true
+
+		if enum, ok := d.enums[fullName]; ok {
+			res.Enums = append(res.Enums, enumTable(fullName, enum))
+			continue
+		}
+		msg, ok := d.messages[fullName]
+		if !ok {
+			continue
+		}
+		table, refs := d.messageTable(fullName, msg)
+		switch {
+		case fullName == reqName || fullName == resName:
+			if fullName == reqName {
+				res.Request = table
+			}
+			if fullName == resName {
+				res.Response = table
+			}
+		default:
+			res.Messages = append(res.Messages, table)
+		}
+		queue = append(queue, refs...)
+	}
+	return res
+}
+
+func (d protoDefs) messageTable(fullName string, msg *protoparser.Message) (model.ProtoMessage, []string) {
+	table := model.ProtoMessage{
+		Name:        fullName,
+		Description: commentText(msg.Comment),
+	}
+	var refs []string
+	addField := func(field *protoparser.Field, typeName string, label string) {
+		table.Fields = append(table.Fields, model.ProtoField{
+			Number:      len(table.Fields) + 1,
+			Name:        field.Name,
+			Type:        typeName,
+			Label:       label,
+			Tag:         field.Sequence,
+			Description: strings.TrimSpace(commentText(field.Comment) + " " + commentText(field.InlineComment)),
+		})
+		refs = append(refs, d.resolve(fullName, field.Type))
+	}
+
+	for _, element := range msg.Elements {
+		switch v := element.(type) {
+		case *protoparser.NormalField:
+			label := ""
+			switch {
+			case v.Repeated:
+				label = "repeated"
+			case v.Optional:
+				label = "optional"
+			case v.Required:
+				label = "required"
+			}
+			addField(v.Field, v.Type, label)
+		case *protoparser.MapField:
+			addField(v.Field, fmt.Sprintf("map<%s, %s>", v.KeyType, v.Type), "map")
+		case *protoparser.Oneof:
+			for _, el := range v.Elements {
+				if f, ok := el.(*protoparser.OneOfField); ok {
+					addField(f.Field, f.Type, "oneof "+v.Name)
+				}
+			}
+		}
+	}
+	return table, refs
+}
+
+func enumTable(fullName string, enum *protoparser.Enum) model.ProtoEnum {
+	table := model.ProtoEnum{
+		Name:        fullName,
+		Description: commentText(enum.Comment),
+	}
+	for _, element := range enum.Elements {
+		if v, ok := element.(*protoparser.EnumField); ok {
+			table.Values = append(table.Values, model.ProtoEnumValue{
+				Number:      len(table.Values) + 1,
+				Name:        v.Name,
+				Value:       v.Integer,
+				Description: strings.TrimSpace(commentText(v.Comment) + " " + commentText(v.InlineComment)),
+			})
+		}
+	}
+	return table
+}
+
+func streamingMode(streamsRequest bool, streamsReturns bool) string {
+	switch {
+	case streamsRequest && streamsReturns:
+		return "Bidirectional streaming"
+	case streamsRequest:
+		return "Client streaming"
+	case streamsReturns:
+		return "Server streaming"
+	default:
+		return "Unary"
+	}
+}
+
+func joinProtoName(scope string, name string) string {
+	if scope == "" {
+		return name
+	}
+	return scope + "." + name
+}
+
+func commentText(comment *protoparser.Comment) string {
+	if comment == nil {
+		return ""
+	}
+	var lines []string
+	for _, line := range comment.Lines {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return strings.Join(lines, " ")
+}
+
+type protoImporter struct{}
+
+func (protoImporter) Name() string { return "gRPC proto file" }
+
+func (protoImporter) Detect(fileName string, data []byte) bool {
+	return hasExt(fileName, ".proto")
+}
+
+func (protoImporter) Import(fileName string, data []byte, related map[string][]byte) (model.APIDocument, error) {
+	files := map[string][]byte{fileName: data}
+	for name, content := range related {
+		if hasExt(name, ".proto") && name != fileName {
+			files[name] = content
+		}
+	}
+	api, err := ParseProtoFiles(fileName, files)
+	if err != nil {
+		return model.APIDocument{}, err
+	}
+	return protoToDocument(api), nil
+}
+
+// protoToDocument maps every RPC to an endpoint whose request and response
+// bodies are the top-level messages; nested messages and enums become the
+// endpoint types
+func protoToDocument(api model.ProtoAPI) model.APIDocument {
+	doc := model.APIDocument{
+		Name:   api.Name,
+		Format: protoImporter{}.Name(),
+	}
+	for _, service := range api.Services {
+		fullService := joinProtoName(service.Package, service.Name)
+		svc := model.APIService{Name: service.Name, Description: service.Description}
+		for _, rpc := range service.RPCs {
+			endpoint := model.APIEndpoint{
+				Name:        rpc.Name,
+				Kind:        "gRPC",
+				Method:      "RPC",
+				URL:         fmt.Sprintf("/%s/%s", fullService, rpc.Name),
+				Description: rpc.Description,
+				Attributes: []model.APIAttribute{
+					{Key: "Service", Value: fullService},
+					{Key: "Streaming", Value: rpc.Streaming},
+					{Key: "Request", Value: rpc.RequestType},
+					{Key: "Response", Value: rpc.ResponseType},
+				},
+			}
+			endpoint.Body = &model.APIBody{Mode: "protobuf", Fields: protoFields(rpc.Request.Fields)}
+			endpoint.Responses = []model.APIResponse{{
+				Status:      rpc.ResponseType,
+				Description: rpc.Response.Description,
+				Body:        &model.APIBody{Mode: "protobuf", Fields: protoFields(rpc.Response.Fields)},
+			}}
+			for _, msg := range rpc.Messages {
+				endpoint.Types = append(endpoint.Types, model.APIType{
+					Name:        msg.Name,
+					Kind:        "Message",
+					Description: msg.Description,
+					Fields:      protoFields(msg.Fields),
+				})
+			}
+			for _, enum := range rpc.Enums {
+				t := model.APIType{
+					Name:        enum.Name,
+					Kind:        "Enum",
+					Description: enum.Description,
+				}
+				for _, v := range enum.Values {
+					t.Values = append(t.Values, model.APIField{
+						Name:        v.Name,
+						Type:        fmt.Sprint(v.Value),
+						Description: strings.TrimSpace(fmt.Sprintf("= %d %s", v.Value, v.Description)),
+					})
+				}
+				endpoint.Types = append(endpoint.Types, t)
+			}
+			svc.Endpoints = append(svc.Endpoints, endpoint)
+		}
+		doc.Services = append(doc.Services, svc)
+	}
+	return doc
+}
+
+func protoFields(rows []model.ProtoField) []model.APIField {
+	var fields []model.APIField
+	for _, row := range rows {
+		fieldType := row.Type
+		if row.Label != "" && row.Label != "map" {
+			fieldType = row.Label + " " + row.Type
+		}
+		fields = append(fields, model.APIField{
+			Name:        row.Name,
+			Type:        fieldType,
+			Required:    row.Label == "required",
+			Description: strings.TrimSpace(fmt.Sprintf("%s (tag %d)", row.Description, row.Tag)),
+		})
+	}
+	return fields
+}
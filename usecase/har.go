@@ -0,0 +1,169 @@
+package usecase
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/arifth/botthie/model"
+)
+
+type harNameValue struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type harLog struct {
+	Log struct {
+		Creator struct {
+			Name string `json:"name"`
+		} `json:"creator"`
+		Pages []struct {
+			Title string `json:"title"`
+		} `json:"pages"`
+		Entries []struct {
+			Request struct {
+				Method      string         `json:"method"`
+				URL         string         `json:"url"`
+				Headers     []harNameValue `json:"headers"`
+				QueryString []harNameValue `json:"queryString"`
+				PostData    *struct {
+					MimeType string         `json:"mimeType"`
+					Text     string         `json:"text"`
+					Params   []harNameValue `json:"params"`
+				} `json:"postData"`
+			} `json:"request"`
+			Response struct {
+				Status     int            `json:"status"`
+				StatusText string         `json:"statusText"`
+				Headers    []harNameValue `json:"headers"`
+				Content    struct {
+					MimeType string `json:"mimeType"`
+					Text     string `json:"text"`
+				} `json:"content"`
+			} `json:"response"`
+		} `json:"entries"`
+	} `json:"log"`
+}
+
+type harImporter struct{}
+
+func (harImporter) Name() string { return "HAR capture" }
+
+func (harImporter) Detect(fileName string, data []byte) bool {
+	if !hasExt(fileName, ".har", ".json") {
+		return false
+	}
+	var probe struct {
+		Log *struct {
+			Entries json.RawMessage `json:"entries"`
+		} `json:"log"`
+	}
+	return json.Unmarshal(data, &probe) == nil && probe.Log != nil && probe.Log.Entries != nil
+}
+
+// Import documents every distinct method and URL of the capture once, using
+// the first recorded exchange as its example
+func (harImporter) Import(fileName string, data []byte, related map[string][]byte) (model.APIDocument, error) {
+	var har harLog
+	if err := json.Unmarshal(data, &har); err != nil {
+		return model.APIDocument{}, fmt.Errorf("failed to parse HAR file: %w", err)
+	}
+	doc := model.APIDocument{
+		Name:   fileName,
+		Format: harImporter{}.Name(),
+	}
+	if len(har.Log.Pages) > 0 && har.Log.Pages[0].Title != "" {
+		doc.Name = har.Log.Pages[0].Title
+	}
+	if har.Log.Creator.Name != "" {
+		doc.Description = "Captured with " + har.Log.Creator.Name
+	}
+
+	seen := map[string]bool{}
+	for _, entry := range har.Log.Entries {
+		req := entry.Request
+		u, err := url.Parse(req.URL)
+		if err != nil {
+			continue
+		}
+		u.RawQuery, u.Fragment = "", ""
+		key := req.Method + " " + u.String()
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		endpoint := model.APIEndpoint{
+			Name:   fmt.Sprintf("%s %s", req.Method, u.Path),
+			Kind:   "HTTP",
+			Method: req.Method,
+			URL:    u.String(),
+		}
+		for _, h := range req.Headers {
+			// HTTP/2 pseudo headers and cookies are capture noise
+			if strings.HasPrefix(h.Name, ":") || strings.EqualFold(h.Name, "cookie") {
+				continue
+			}
+			endpoint.Headers = append(endpoint.Headers, model.APIParameter{Name: h.Name, In: "header", Example: h.Value})
+		}
+		for _, q := range req.QueryString {
+			endpoint.Parameters = append(endpoint.Parameters, model.APIParameter{
+				Name:        q.Name,
+				In:          "query",
+				Type:        "string",
+				Example:     q.Value,
+				Description: makeReadable(q.Name),
+			})
+		}
+		example := model.APIExample{Name: "Recorded exchange"}
+		if pd := req.PostData; pd != nil {
+			example.Request = pd.Text
+			body := &model.APIBody{MediaType: pd.MimeType, Mode: "raw"}
+			if len(pd.Params) > 0 {
+				body.Mode = "urlencoded"
+				for _, p := range pd.Params {
+					body.Fields = append(body.Fields, model.APIField{
+						Name:        p.Name,
+						Type:        determineType(p.Value),
+						Description: makeReadable(p.Name),
+					})
+				}
+			} else {
+				body.Fields = parseJSONBodyFields(pd.Text)
+			}
+			endpoint.Body = body
+		}
+
+		res := entry.Response
+		response := model.APIResponse{
+			Status:      strings.TrimSpace(fmt.Sprintf("%d %s", res.Status, res.StatusText)),
+			Description: res.StatusText,
+		}
+		for _, h := range res.Headers {
+			if strings.EqualFold(h.Name, "set-cookie") {
+				continue
+			}
+			response.Headers = append(response.Headers, model.APIParameter{Name: h.Name, In: "header", Example: h.Value})
+		}
+		if res.Content.Text != "" {
+			response.Body = &model.APIBody{
+				MediaType: res.Content.MimeType,
+				Mode:      "raw",
+				Fields:    parseJSONBodyFields(res.Content.Text),
+			}
+		}
+		endpoint.Responses = []model.APIResponse{response}
+		example.Status = response.Status
+		example.Response = res.Content.Text
+		if example.Request != "" || example.Response != "" {
+			endpoint.Examples = []model.APIExample{example}
+		}
+		addEndpoint(&doc, u.Host, endpoint)
+	}
+	if endpointCount(doc) == 0 {
+		return doc, fmt.Errorf("no requests found: %w", ErrNothingToDocument)
+	}
+	return doc, nil
+}
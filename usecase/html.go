@@ -4,7 +4,8 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
-	"log"
+	"net/url"
+	"sort"
 	"strings"
 	"text/template"
 
@@ -13,15 +14,17 @@ import (
 
 func extractURL(urlInterface interface{}) string {
 	rw := urlInterface.(model.PostmanRequest)
+	// the url of a request is either a plain string or an object
+	if str, ok := rw.URL.(string); ok {
+		return str
+	}
 	raw, ok := rw.URL.(map[string]interface{})
-	fmt.Println(raw)
 	if !ok {
 		return ""
 	}
 	for key, value := range raw {
 		if key == "raw" {
-			fmt.Println(value)
-			vl := value.(string)
+			vl, _ := value.(string)
 			return vl
 		}
 	}
@@ -106,86 +109,177 @@ func determineType(value interface{}) string {
 }
 
 // parseJSONBodyFields parses JSON body and extracts field names with their types
-func parseJSONBodyFields(rawBody string) []model.BodyField {
+func parseJSONBodyFields(rawBody string) []model.APIField {
 	var jsonData map[string]interface{}
 	err := json.Unmarshal([]byte(rawBody), &jsonData)
 	if err != nil {
 		return nil
 	}
 
-	var fields []model.BodyField
-	index := 1
-	for key, value := range jsonData {
-		fields = append(fields, model.BodyField{
-			Number:      index,
-			Field:       key,
+	var keys []string
+	for key := range jsonData {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var fields []model.APIField
+	for _, key := range keys {
+		value := jsonData[key]
+		fields = append(fields, model.APIField{
+			Name:        key,
 			Type:        determineType(value),
-			Mandatory:   "No", // Default to No, can be customized
+			Required:    false, // Default to No, can be customized
 			Description: generateDescription(key, value),
 		})
-		index++
 	}
 
 	return fields
 }
-func (Usecase) ConvertToHTML(collection model.PostmanCollection, dataTempl string, item model.PostmanItem) string {
-	// Extract request data
+
+// ConvertToHTML renders the page of one endpoint of an imported document
+func (Usecase) ConvertToHTML(doc model.APIDocument, dataTempl string, service model.APIService, endpoint model.APIEndpoint) string {
+	// Prepare template data
+	data := model.TemplateData{
+		CollectionName: doc.Name,
+		Requests:       requestData(service, endpoint),
+	}
+	return executeTemplate("apiBook", dataTempl, data)
+}
+
+// requestData derives the template view of an endpoint
+func requestData(service model.APIService, endpoint model.APIEndpoint) model.RequestData {
 	reqData := model.RequestData{
-		Name:    item.Name,
-		Method:  item.Request.Method,
-		URL:     extractURL(item.Request),
-		Headers: item.Request.Header,
-	}
-
-	// Parse body based on mode
-	if item.Request.Body != nil {
-		reqData.BodyMode = item.Request.Body.Mode
-
-		// Check if body is JSON with fields
-		if item.Request.Body.Mode == "raw" && item.Request.Body.Raw != "" {
-			// Try to parse as JSON to extract fields
-			bodyFields := parseJSONBodyFields(item.Request.Body.Raw)
-			if len(bodyFields) > 0 {
-				reqData.BodyFields = bodyFields
-			} else {
-				// If not valid JSON or no fields, just show raw
-				reqData.Body = item.Request.Body.Raw
-			}
-		} else if item.Request.Body.Mode == "formdata" && len(item.Request.Body.FormData) > 0 {
-			// Parse form-data fields
-			for idx, field := range item.Request.Body.FormData {
-				reqData.BodyFields = append(reqData.BodyFields, model.BodyField{
-					Number:      idx + 1,
-					Field:       field.Key,
-					Type:        determineType(field.Value),
-					Mandatory:   "No",
-					Description: makeReadable(field.Key),
-				})
-			}
-		} else if item.Request.Body.Mode == "urlencoded" && len(item.Request.Body.URLEncoded) > 0 {
-			// Parse URL-encoded fields
-			for idx, field := range item.Request.Body.URLEncoded {
-				reqData.BodyFields = append(reqData.BodyFields, model.BodyField{
-					Number:      idx + 1,
-					Field:       field.Key,
-					Type:        determineType(field.Value),
-					Mandatory:   "No",
-					Description: makeReadable(field.Key),
-				})
-			}
-		} else if item.Request.Body.Raw != "" {
-			reqData.Body = item.Request.Body.Raw
+		Service:     service.Name,
+		Name:        endpoint.Name,
+		Kind:        endpoint.Kind,
+		Method:      endpoint.Method,
+		URL:         endpoint.URL,
+		Description: endpoint.Description,
+		Deprecated:  endpoint.Deprecated,
+		Attributes:  endpoint.Attributes,
+		Auth:        authRows(endpoint.Auth),
+		Parameters:  parameterRows(endpoint.Parameters),
+		Examples:    endpoint.Examples,
+		Types:       typeRows(endpoint.Types),
+	}
+	for _, h := range endpoint.Headers {
+		reqData.Headers = append(reqData.Headers, model.HeaderField{
+			Key:   h.Name,
+			Value: firstNonEmpty(h.Example, h.Default, h.Description),
+		})
+	}
+	if endpoint.Body != nil {
+		reqData.BodyMode = endpoint.Body.Mode
+		reqData.Body = endpoint.Body.Raw
+		reqData.BodyFields = fieldRows(endpoint.Body.Fields)
+	}
+	for _, r := range endpoint.Responses {
+		res := model.ResponseData{
+			Status:      r.Status,
+			Description: r.Description,
+			Headers:     parameterRows(r.Headers),
+		}
+		if r.Body != nil {
+			res.Body = r.Body.Raw
+			res.BodyFields = fieldRows(r.Body.Fields)
 		}
+		reqData.Responses = append(reqData.Responses, res)
 	}
-	// Prepare template data
-	data := model.TemplateData{
-		CollectionName: collection.Info.Name,
-		Requests:       reqData,
+	return reqData
+}
+
+// ConvertTypeToHTML renders a type reference page
+func (Usecase) ConvertTypeToHTML(doc model.APIDocument, dataTempl string, t model.APIType) string {
+	rows := typeRows([]model.APIType{t})
+	data := model.TypeTemplateData{
+		CollectionName: doc.Name,
+		Type:           rows[0],
 	}
+	return executeTemplate("type", dataTempl, data)
+}
+
+func fieldRows(fields []model.APIField) []model.BodyField {
+	var rows []model.BodyField
+	for idx, f := range fields {
+		description := f.Description
+		if f.Default != "" {
+			description = strings.TrimSpace(fmt.Sprintf("%s (default: %s)", description, f.Default))
+		}
+		rows = append(rows, model.BodyField{
+			Number:      idx + 1,
+			Field:       f.Name,
+			Type:        f.Type,
+			Mandatory:   yesNo(f.Required),
+			Description: description,
+		})
+	}
+	return rows
+}
+
+func parameterRows(params []model.APIParameter) []model.ParameterField {
+	var rows []model.ParameterField
+	for idx, p := range params {
+		description := p.Description
+		if p.Example != "" {
+			description = strings.TrimSpace(fmt.Sprintf("%s (example: %s)", description, p.Example))
+		}
+		rows = append(rows, model.ParameterField{
+			Number:      idx + 1,
+			Name:        p.Name,
+			In:          p.In,
+			Type:        p.Type,
+			Mandatory:   yesNo(p.Required),
+			Default:     p.Default,
+			Description: description,
+		})
+	}
+	return rows
+}
+
+func authRows(auth []model.APIAuth) []model.AuthData {
+	var rows []model.AuthData
+	for _, a := range auth {
+		location := a.In
+		if a.Key != "" {
+			location = strings.TrimSpace(a.In + " " + a.Key)
+		}
+		rows = append(rows, model.AuthData{
+			Name:        a.Name,
+			Type:        a.Type,
+			Location:    location,
+			Scopes:      strings.Join(a.Scopes, ", "),
+			Description: a.Description,
+		})
+	}
+	return rows
+}
+
+func typeRows(types []model.APIType) []model.TypeData {
+	var rows []model.TypeData
+	for _, t := range types {
+		rows = append(rows, model.TypeData{
+			Name:        t.Name,
+			Kind:        t.Kind,
+			Description: t.Description,
+			Fields:      fieldRows(t.Fields),
+			Values:      fieldRows(t.Values),
+		})
+	}
+	return rows
+}
+
+func yesNo(b bool) string {
+	if b {
+		return "Yes"
+	}
+	return "No"
+}
+
+func executeTemplate(name string, dataTempl string, data interface{}) string {
 	// Parse and execute template
-	t, err := template.New("postman").Parse(dataTempl)
+	t, err := template.New(name).Funcs(templateFuncs).Parse(dataTempl)
 	if err != nil {
-		log.Fatal("error while parsing template", err)
+		return fmt.Sprintf("Template parsing error: %v", err)
 	}
 
 	var buf bytes.Buffer
@@ -193,6 +287,15 @@ func (Usecase) ConvertToHTML(collection model.PostmanCollection, dataTempl strin
 	if err != nil {
 		return fmt.Sprintf("Template execution error: %v", err)
 	}
-
 	return buf.String()
 }
+
+// httpURL returns raw when it is an http or https URL and empty otherwise, so
+// that links in the rendered pages cannot run javascript: or data: URLs
+func httpURL(raw string) string {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return ""
+	}
+	return strings.TrimSpace(raw)
+}
@@ -0,0 +1,110 @@
+package usecase
+
+import (
+	"errors"
+	"path"
+	"strings"
+
+	"github.com/arifth/botthie/model"
+)
+
+var (
+	// ErrUnknownFormat is returned when no importer recognises a document
+	ErrUnknownFormat = errors.New("unrecognised document format")
+	// ErrNothingToDocument is returned for documents that only support other
+	// documents of the same upload, e.g. a .proto file without services
+	ErrNothingToDocument = errors.New("nothing to document")
+)
+
+// Importer turns an uploaded document into the format-neutral API model
+type Importer interface {
+	// Name describes the format to users, e.g. in archive summaries
+	Name() string
+	// Detect sniffs the file name and content of an uploaded document
+	Detect(fileName string, data []byte) bool
+	// Import parses the document. related holds the other files of the same
+	// upload so imports, XSD types and environments can be resolved
+	Import(fileName string, data []byte, related map[string][]byte) (model.APIDocument, error)
+}
+
+// importers is consulted in order, so formats with a distinctive marker come
+// before the ones detected by shape alone
+var importers = []Importer{
+	graphQLImporter{},
+	asyncAPIImporter{},
+	openAPIImporter{},
+	wsdlImporter{},
+	protoImporter{},
+	harImporter{},
+	insomniaImporter{},
+	postmanImporter{},
+}
+
+// RegisterImporter adds an importer that is consulted before the built-in ones
+func RegisterImporter(i Importer) {
+	importers = append([]Importer{i}, importers...)
+}
+
+// DetectImporter returns the importer that recognises the document
+func DetectImporter(fileName string, data []byte) (Importer, error) {
+	for _, i := range importers {
+		if i.Detect(fileName, data) {
+			return i, nil
+		}
+	}
+	return nil, ErrUnknownFormat
+}
+
+// ImportDocument detects the format of a document and imports it
+func ImportDocument(fileName string, data []byte, related map[string][]byte) (model.APIDocument, Importer, error) {
+	i, err := DetectImporter(fileName, data)
+	if err != nil {
+		return model.APIDocument{}, nil, err
+	}
+	doc, err := i.Import(fileName, data, related)
+	doc.SourceName, doc.Source = path.Base(fileName), data
+	return doc, i, err
+}
+
+// hasExt reports whether fileName ends with one of the extensions
+func hasExt(fileName string, exts ...string) bool {
+	ext := strings.ToLower(path.Ext(fileName))
+	for _, e := range exts {
+		if ext == e {
+			return true
+		}
+	}
+	return false
+}
+
+// isStructuredDocument reports whether fileName may hold JSON or YAML
+func isStructuredDocument(fileName string) bool {
+	return hasExt(fileName, ".json", ".yaml", ".yml") || path.Ext(fileName) == ""
+}
+
+// addEndpoint appends an endpoint to the named service of the document,
+// creating the service on first use
+func addEndpoint(doc *model.APIDocument, service string, endpoint model.APIEndpoint) {
+	for i := range doc.Services {
+		if doc.Services[i].Name == service {
+			doc.Services[i].Endpoints = append(doc.Services[i].Endpoints, endpoint)
+			return
+		}
+	}
+	doc.Services = append(doc.Services, model.APIService{Name: service, Endpoints: []model.APIEndpoint{endpoint}})
+}
+
+// endpointCount returns the number of endpoints across all services
+func endpointCount(doc model.APIDocument) int {
+	n := 0
+	for _, svc := range doc.Services {
+		n += len(svc.Endpoints)
+	}
+	return n
+}
+
+// PageCount returns the number of pages a document renders to: one per
+// endpoint and one per shared type
+func PageCount(doc model.APIDocument) int {
+	return endpointCount(doc) + len(doc.Types)
+}
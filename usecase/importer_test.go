@@ -0,0 +1,285 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/arifth/botthie/model"
+)
+
+// testdata returns the fixtures of testdata by file name, the related files
+// of every import
+func testdata(t *testing.T) map[string][]byte {
+	t.Helper()
+	paths, err := filepath.Glob("testdata/*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := map[string][]byte{}
+	for _, p := range paths {
+		data, err := os.ReadFile(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		files[filepath.Base(p)] = data
+	}
+	return files
+}
+
+// endpointList lists the endpoints of a document as "service: METHOD name url"
+func endpointList(doc model.APIDocument) []string {
+	var list []string
+	for _, s := range doc.Services {
+		for _, e := range s.Endpoints {
+			list = append(list, fmt.Sprintf("%s: %s %s %s", s.Name, e.Method, e.Name, e.URL))
+		}
+	}
+	return list
+}
+
+// findEndpoint returns the endpoint named name of a document
+func findEndpoint(t *testing.T, doc model.APIDocument, name string) model.APIEndpoint {
+	t.Helper()
+	for _, s := range doc.Services {
+		for _, e := range s.Endpoints {
+			if e.Name == name {
+				return e
+			}
+		}
+	}
+	t.Fatalf("no endpoint %s in %v", name, endpointList(doc))
+	return model.APIEndpoint{}
+}
+
+// fieldNames returns the names of the fields of a body
+func fieldNames(body *model.APIBody) []string {
+	if body == nil {
+		return nil
+	}
+	var names []string
+	for _, f := range body.Fields {
+		names = append(names, f.Name)
+	}
+	return names
+}
+
+func TestImportDocument(t *testing.T) {
+	files := testdata(t)
+	tests := []struct {
+		file      string
+		importer  string
+		name      string
+		endpoints []string
+		check     func(t *testing.T, doc model.APIDocument)
+	}{
+		{
+			file:     "pets.postman_collection.json",
+			importer: "Postman collection",
+			name:     "Pet Store",
+			endpoints: []string{
+				"Users: GET Get user https://api.example.com/users/1",
+				"Users / Admin: DELETE Delete user https://api.example.com/users/1",
+				": GET Health https://api.example.com/health",
+			},
+			check: func(t *testing.T, doc model.APIDocument) {
+				if doc.Services[0].Description != "Accounts of the store" {
+					t.Errorf("folder description = %q", doc.Services[0].Description)
+				}
+				if auth := findEndpoint(t, doc, "Delete user").Auth; len(auth) != 1 {
+					t.Errorf("Delete user auth = %+v, want the bearer auth of its folder", auth)
+				}
+				if auth := findEndpoint(t, doc, "Get user").Auth; len(auth) != 0 {
+					t.Errorf("Get user auth = %+v, want none", auth)
+				}
+			},
+		},
+		{
+			file:     "openapi.yaml",
+			importer: "OpenAPI document",
+			name:     "Pet Store",
+			endpoints: []string{
+				"Pets: GET List pets https://api.example.com/pets",
+				"Pets: POST Create pet https://api.example.com/pets",
+			},
+			check: func(t *testing.T, doc model.APIDocument) {
+				if got := fieldNames(findEndpoint(t, doc, "Create pet").Body); !reflect.DeepEqual(got, []string{"id", "name"}) {
+					t.Errorf("Create pet body fields = %v", got)
+				}
+			},
+		},
+		{
+			file:     "pets.graphql",
+			importer: "GraphQL schema",
+			name:     "pets.graphql",
+			endpoints: []string{
+				"Query: QUERY pets ",
+				"Mutation: MUTATION createPet ",
+			},
+			check: func(t *testing.T, doc model.APIDocument) {
+				pets := findEndpoint(t, doc, "pets")
+				if pets.Description != "Lists the pets" || len(pets.Parameters) != 1 {
+					t.Errorf("pets = %+v", pets)
+				}
+				if dep := findEndpoint(t, doc, "createPet").Deprecated; dep != "use addPet" {
+					t.Errorf("createPet deprecated = %q", dep)
+				}
+				if len(doc.Types) != 2 {
+					t.Errorf("types = %+v, want NewPet and Pet", doc.Types)
+				}
+			},
+		},
+		{
+			file:      "introspection.json",
+			importer:  "GraphQL schema",
+			name:      "introspection.json",
+			endpoints: []string{"Query: QUERY pet "},
+			check: func(t *testing.T, doc model.APIDocument) {
+				pet := findEndpoint(t, doc, "pet")
+				if len(pet.Responses) != 1 || !reflect.DeepEqual(fieldNames(pet.Responses[0].Body), []string{"id", "name"}) {
+					t.Errorf("pet responses = %+v", pet.Responses)
+				}
+			},
+		},
+		{
+			file:     "pets.proto",
+			importer: "gRPC proto file",
+			name:     "pets.proto",
+			endpoints: []string{
+				"PetService: RPC GetPet /pets.v1.PetService/GetPet",
+				"PetService: RPC WatchPets /pets.v1.PetService/WatchPets",
+			},
+			check: func(t *testing.T, doc model.APIDocument) {
+				// Pet is defined in the imported common.proto
+				get := findEndpoint(t, doc, "GetPet")
+				if len(get.Responses) != 1 || !reflect.DeepEqual(fieldNames(get.Responses[0].Body), []string{"id", "name", "tags"}) {
+					t.Errorf("GetPet responses = %+v", get.Responses)
+				}
+				var streaming string
+				for _, a := range findEndpoint(t, doc, "WatchPets").Attributes {
+					if a.Key == "Streaming" {
+						streaming = a.Value
+					}
+				}
+				if streaming != "Server streaming" {
+					t.Errorf("WatchPets streaming = %q", streaming)
+				}
+			},
+		},
+		{
+			file:     "asyncapi.yaml",
+			importer: "AsyncAPI document",
+			name:     "Pet Events",
+			endpoints: []string{
+				"pets/adopt: PUBLISH adoptPet pets/adopt",
+				"pets/created: SUBSCRIBE onPetCreated pets/created",
+			},
+			check: func(t *testing.T, doc model.APIDocument) {
+				created := findEndpoint(t, doc, "onPetCreated")
+				if created.Body == nil || len(created.Body.Fields) != 2 || !created.Body.Fields[0].Required {
+					t.Errorf("onPetCreated payload = %+v", created.Body)
+				}
+			},
+		},
+		{
+			file:      "pets.wsdl",
+			importer:  "WSDL document",
+			name:      "PetStore",
+			endpoints: []string{"PetPort: POST GetPet https://api.example.com/soap/pets"},
+			check: func(t *testing.T, doc model.APIDocument) {
+				// the elements are defined in the imported types.xsd
+				get := findEndpoint(t, doc, "GetPet")
+				if got := fieldNames(get.Body); !reflect.DeepEqual(got, []string{"id"}) {
+					t.Errorf("GetPet request fields = %v", got)
+				}
+				if len(get.Responses) != 1 || !reflect.DeepEqual(fieldNames(get.Responses[0].Body), []string{"name", "tag"}) {
+					t.Errorf("GetPet responses = %+v", get.Responses)
+				}
+			},
+		},
+		{
+			file:     "pets.har",
+			importer: "HAR capture",
+			name:     "pets.har",
+			endpoints: []string{
+				"api.example.com: GET GET /pets https://api.example.com/pets",
+				"api.example.com: POST POST /pets https://api.example.com/pets",
+				"cdn.example.com: GET GET /logo.png https://cdn.example.com/logo.png",
+			},
+			check: func(t *testing.T, doc model.APIDocument) {
+				list := findEndpoint(t, doc, "GET /pets")
+				if len(list.Parameters) != 1 || list.Parameters[0].Name != "limit" {
+					t.Errorf("GET /pets parameters = %+v", list.Parameters)
+				}
+				create := findEndpoint(t, doc, "POST /pets")
+				if len(create.Responses) != 1 || create.Responses[0].Status != "201 Created" || len(create.Examples) != 1 {
+					t.Errorf("POST /pets responses = %+v examples = %+v", create.Responses, create.Examples)
+				}
+			},
+		},
+		{
+			file:     "insomnia.json",
+			importer: "Insomnia export",
+			name:     "Pet Store",
+			endpoints: []string{
+				"Pets: GET List pets https://api.example.com/pets",
+				"Pets: POST Create pet https://api.example.com/pets",
+			},
+			check: func(t *testing.T, doc model.APIDocument) {
+				if got := fieldNames(findEndpoint(t, doc, "Create pet").Body); !reflect.DeepEqual(got, []string{"name"}) {
+					t.Errorf("Create pet body fields = %v", got)
+				}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			importer, err := DetectImporter(tt.file, files[tt.file])
+			if err != nil || importer.Name() != tt.importer {
+				t.Fatalf("DetectImporter = %v, %v, want %s", importer, err, tt.importer)
+			}
+			doc, _, err := ImportDocument(tt.file, files[tt.file], files)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if doc.Name != tt.name {
+				t.Errorf("name = %q, want %q", doc.Name, tt.name)
+			}
+			if got := endpointList(doc); !reflect.DeepEqual(got, tt.endpoints) {
+				t.Errorf("endpoints =\n%q\nwant\n%q", got, tt.endpoints)
+			}
+			tt.check(t, doc)
+		})
+	}
+}
+
+func TestDetectImporterRejects(t *testing.T) {
+	files := testdata(t)
+	tests := []struct {
+		file string
+		data []byte
+	}{
+		{"dev.postman_environment.json", files["dev.postman_environment.json"]},
+		{"types.xsd", files["types.xsd"]},
+		{"notes.txt", []byte("GET /pets")},
+		// the content decides, not the extension alone
+		{"collection.json", []byte(`{"name": "not a collection"}`)},
+		{"pets.yaml", files["pets.postman_collection.json"]},
+	}
+	for _, tt := range tests {
+		if importer, err := DetectImporter(tt.file, tt.data); !errors.Is(err, ErrUnknownFormat) {
+			t.Errorf("DetectImporter(%s) = %v, %v, want ErrUnknownFormat", tt.file, importer, err)
+		}
+	}
+}
+
+func TestImportSupportingFile(t *testing.T) {
+	files := testdata(t)
+	// a .proto file without services only supports the ones importing it
+	if _, _, err := ImportDocument("common.proto", files["common.proto"], files); !errors.Is(err, ErrNothingToDocument) {
+		t.Errorf("ImportDocument(common.proto) error = %v, want ErrNothingToDocument", err)
+	}
+}
@@ -0,0 +1,196 @@
+package usecase
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/arifth/botthie/model"
+)
+
+type insomniaResource struct {
+	Type        string                 `json:"_type"`
+	ID          string                 `json:"_id"`
+	ParentID    string                 `json:"parentId"`
+	Name        string                 `json:"name"`
+	Description string                 `json:"description"`
+	Method      string                 `json:"method"`
+	URL         string                 `json:"url"`
+	Data        map[string]interface{} `json:"data"`
+	Headers     []insomniaPair         `json:"headers"`
+	Parameters  []insomniaPair         `json:"parameters"`
+	Auth        map[string]interface{} `json:"authentication"`
+	Body        struct {
+		MimeType string         `json:"mimeType"`
+		Text     string         `json:"text"`
+		Params   []insomniaPair `json:"params"`
+	} `json:"body"`
+}
+
+type insomniaPair struct {
+	Name        string `json:"name"`
+	Value       string `json:"value"`
+	Description string `json:"description"`
+	Disabled    bool   `json:"disabled"`
+}
+
+// insomniaVariable matches both {{ _.name }} and the legacy {{ name }} syntax
+var insomniaVariable = regexp.MustCompile(`{{\s*(?:_\.)?([\w.-]+)\s*}}`)
+
+type insomniaImporter struct{}
+
+func (insomniaImporter) Name() string { return "Insomnia export" }
+
+func (insomniaImporter) Detect(fileName string, data []byte) bool {
+	if !hasExt(fileName, ".json") {
+		return false
+	}
+	var probe struct {
+		Type      string          `json:"_type"`
+		Resources json.RawMessage `json:"resources"`
+	}
+	return json.Unmarshal(data, &probe) == nil && probe.Type == "export" && probe.Resources != nil
+}
+
+// Import documents the requests of an Insomnia v4 export, using request
+// groups as folders and the environments to resolve template variables
+func (insomniaImporter) Import(fileName string, data []byte, related map[string][]byte) (model.APIDocument, error) {
+	var export struct {
+		Resources []insomniaResource `json:"resources"`
+	}
+	if err := json.Unmarshal(data, &export); err != nil {
+		return model.APIDocument{}, fmt.Errorf("failed to parse Insomnia export: %w", err)
+	}
+
+	doc := model.APIDocument{
+		Name:   fileName,
+		Format: insomniaImporter{}.Name(),
+	}
+	byID := map[string]insomniaResource{}
+	vars := map[string]string{}
+	for _, res := range export.Resources {
+		byID[res.ID] = res
+		switch res.Type {
+		case "workspace":
+			doc.Name = res.Name
+			doc.Description = res.Description
+		case "environment":
+			for k, v := range res.Data {
+				if _, ok := vars[k]; !ok {
+					vars[k] = scalarString(v)
+				}
+			}
+		}
+	}
+	resolve := func(s string) string {
+		return insomniaVariable.ReplaceAllStringFunc(s, func(match string) string {
+			name := insomniaVariable.FindStringSubmatch(match)[1]
+			if v, ok := vars[name]; ok {
+				return v
+			}
+			return match
+		})
+	}
+
+	for _, res := range export.Resources {
+		if res.Type != "request" {
+			continue
+		}
+		var folders []string
+		for parent, ok := byID[res.ParentID]; ok && parent.Type == "request_group"; parent, ok = byID[parent.ParentID] {
+			folders = append([]string{parent.Name}, folders...)
+		}
+		endpoint := model.APIEndpoint{
+			Name:        res.Name,
+			Kind:        "HTTP",
+			Method:      res.Method,
+			URL:         resolve(res.URL),
+			Description: res.Description,
+			Auth:        insomniaAuth(res.Auth),
+		}
+		for _, h := range res.Headers {
+			if h.Disabled {
+				continue
+			}
+			endpoint.Headers = append(endpoint.Headers, model.APIParameter{
+				Name:        h.Name,
+				In:          "header",
+				Example:     resolve(h.Value),
+				Description: h.Description,
+			})
+		}
+		for _, p := range res.Parameters {
+			if p.Disabled {
+				continue
+			}
+			endpoint.Parameters = append(endpoint.Parameters, model.APIParameter{
+				Name:        p.Name,
+				In:          "query",
+				Type:        "string",
+				Example:     resolve(p.Value),
+				Description: firstNonEmpty(p.Description, makeReadable(p.Name)),
+			})
+		}
+		switch {
+		case res.Body.Text != "":
+			text := resolve(res.Body.Text)
+			endpoint.Body = &model.APIBody{
+				MediaType: res.Body.MimeType,
+				Mode:      "raw",
+				Raw:       text,
+				Fields:    parseJSONBodyFields(text),
+			}
+		case len(res.Body.Params) > 0:
+			body := &model.APIBody{MediaType: res.Body.MimeType, Mode: "formdata"}
+			if strings.Contains(res.Body.MimeType, "urlencoded") {
+				body.Mode = "urlencoded"
+			}
+			for _, p := range res.Body.Params {
+				if p.Disabled {
+					continue
+				}
+				body.Fields = append(body.Fields, model.APIField{
+					Name:        p.Name,
+					Type:        determineType(p.Value),
+					Description: firstNonEmpty(p.Description, makeReadable(p.Name)),
+				})
+			}
+			endpoint.Body = body
+		}
+		addEndpoint(&doc, strings.Join(folders, " / "), endpoint)
+	}
+	if endpointCount(doc) == 0 {
+		return doc, fmt.Errorf("no requests found: %w", ErrNothingToDocument)
+	}
+	return doc, nil
+}
+
+func insomniaAuth(auth map[string]interface{}) []model.APIAuth {
+	authType := stringField(auth, "type")
+	if authType == "" || authType == "none" {
+		return nil
+	}
+	if disabled, _ := auth["disabled"].(bool); disabled {
+		return nil
+	}
+	res := model.APIAuth{Name: authType, Type: authType, In: "header", Key: "Authorization"}
+	switch authType {
+	case "bearer":
+		res.Description = "Bearer token in the Authorization header"
+	case "basic", "digest", "ntlm":
+		res.Description = "Username and password in the Authorization header"
+	case "apikey":
+		res.Key = stringField(auth, "key")
+		if stringField(auth, "addTo") == "queryParams" {
+			res.In = "query"
+		}
+		res.Description = fmt.Sprintf("API key sent as the %s %s", res.Key, res.In)
+	case "oauth2":
+		res.Description = "OAuth 2.0 access token"
+		res.Scopes = strings.Fields(stringField(auth, "scope"))
+	default:
+		res.In, res.Key = "", ""
+	}
+	return []model.APIAuth{res}
+}
@@ -0,0 +1,297 @@
+package usecase
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/arifth/botthie/model"
+)
+
+var slugInvalid = regexp.MustCompile(`[^a-z0-9]+`)
+
+// RenderMarkdown renders a document as GitHub-flavoured Markdown files: a
+// README.md index, one file per endpoint in a folder per service and one file
+// per shared type, keyed by their path
+func (Usecase) RenderMarkdown(doc model.APIDocument, uc *Usecase) map[string][]byte {
+	files := map[string][]byte{}
+	paths := pathAllocator{}
+
+	var index strings.Builder
+	index.WriteString(fmt.Sprintf("# %s\n\n", doc.Name))
+	if doc.Description != "" {
+		index.WriteString(doc.Description + "\n\n")
+	}
+	var facts []string
+	if doc.Format != "" {
+		facts = append(facts, "Format: "+doc.Format)
+	}
+	if doc.Version != "" {
+		facts = append(facts, "Version: "+doc.Version)
+	}
+	if len(facts) > 0 {
+		index.WriteString(strings.Join(facts, " · ") + "\n\n")
+	}
+	if len(doc.Auth) > 0 {
+		index.WriteString("## Authentication\n\n")
+		writeAuthTable(&index, authRows(doc.Auth))
+	}
+
+	for _, service := range doc.Services {
+		dir := slugify(firstNonEmpty(service.Name, "endpoints"))
+		index.WriteString(fmt.Sprintf("## %s\n\n", firstNonEmpty(service.Name, "Endpoints")))
+		if service.Description != "" {
+			index.WriteString(service.Description + "\n\n")
+		}
+		if service.BaseURL != "" {
+			index.WriteString(fmt.Sprintf("Base URL: `%s`\n\n", service.BaseURL))
+		}
+		for _, endpoint := range service.Endpoints {
+			file := paths.next(dir, endpoint.Name, ".md")
+			files[file] = []byte(uc.ConvertToMarkdown(doc, service, endpoint))
+			index.WriteString(fmt.Sprintf("- [%s](%s)", markdownText(endpoint.Name), file))
+			if endpoint.Method != "" {
+				index.WriteString(fmt.Sprintf(" `%s`", endpoint.Method))
+			}
+			if endpoint.Deprecated != "" {
+				index.WriteString(" _(deprecated)_")
+			}
+			index.WriteString("\n")
+		}
+		index.WriteString("\n")
+	}
+
+	if len(doc.Types) > 0 {
+		index.WriteString("## Types\n\n")
+		for _, t := range doc.Types {
+			file := paths.next("types", t.Name, ".md")
+			files[file] = []byte(uc.ConvertTypeToMarkdown(doc, t))
+			index.WriteString(fmt.Sprintf("- [%s](%s) %s\n", markdownText(t.Name), file, t.Kind))
+		}
+		index.WriteString("\n")
+	}
+
+	files["README.md"] = []byte(strings.TrimRight(index.String(), "\n") + "\n")
+	return files
+}
+
+// ConvertToMarkdown renders the Markdown page of one endpoint
+func (Usecase) ConvertToMarkdown(doc model.APIDocument, service model.APIService, endpoint model.APIEndpoint) string {
+	req := requestData(service, endpoint)
+	var sb strings.Builder
+
+	sb.WriteString(fmt.Sprintf("# %s\n\n", req.Name))
+	var facts []string
+	for _, fact := range []string{doc.Name, req.Service, req.Kind} {
+		if fact != "" {
+			facts = append(facts, fact)
+		}
+	}
+	sb.WriteString(fmt.Sprintf("> %s\n\n", strings.Join(facts, " · ")))
+	sb.WriteString(fmt.Sprintf("```\n%s\n```\n\n", strings.TrimSpace(req.Method+" "+req.URL)))
+	if req.Description != "" {
+		sb.WriteString(req.Description + "\n\n")
+	}
+	if req.Deprecated != "" {
+		sb.WriteString(fmt.Sprintf("**Deprecated:** %s\n\n", req.Deprecated))
+	}
+
+	if len(req.Attributes) > 0 {
+		var rows [][]string
+		for _, a := range req.Attributes {
+			rows = append(rows, []string{a.Key, a.Value})
+		}
+		writeTable(&sb, []string{"Key", "Value"}, rows)
+	}
+	if len(req.Auth) > 0 {
+		sb.WriteString("## Authentication\n\n")
+		writeAuthTable(&sb, req.Auth)
+	}
+	if len(req.Headers) > 0 {
+		sb.WriteString("## Headers\n\n")
+		var rows [][]string
+		for _, h := range req.Headers {
+			rows = append(rows, []string{h.Key, h.Value})
+		}
+		writeTable(&sb, []string{"Key", "Value"}, rows)
+	}
+	if len(req.Parameters) > 0 {
+		sb.WriteString("## Parameters\n\n")
+		var rows [][]string
+		for _, p := range req.Parameters {
+			rows = append(rows, []string{fmt.Sprint(p.Number), "**" + p.Name + "**", p.In, p.Type, p.Mandatory, p.Default, p.Description})
+		}
+		writeTable(&sb, []string{"No.", "Name", "In", "Type", "Mandatory", "Default", "Description"}, rows)
+	}
+	if len(req.BodyFields) > 0 || req.Body != "" {
+		sb.WriteString("## Request Body\n\n")
+		writeFieldTable(&sb, req.BodyFields)
+		writeCodeBlock(&sb, req.Body)
+	}
+	for _, r := range req.Responses {
+		sb.WriteString(fmt.Sprintf("## Response %s\n\n", r.Status))
+		if r.Description != "" {
+			sb.WriteString(r.Description + "\n\n")
+		}
+		if len(r.Headers) > 0 {
+			var rows [][]string
+			for _, h := range r.Headers {
+				rows = append(rows, []string{fmt.Sprint(h.Number), "**" + h.Name + "**", h.Type, h.Description})
+			}
+			writeTable(&sb, []string{"No.", "Header", "Type", "Description"}, rows)
+		}
+		writeFieldTable(&sb, r.BodyFields)
+		writeCodeBlock(&sb, r.Body)
+	}
+	if len(req.Examples) > 0 {
+		sb.WriteString("## Examples\n\n")
+		for _, e := range req.Examples {
+			sb.WriteString(fmt.Sprintf("### %s\n\n", firstNonEmpty(e.Name, "Example")))
+			if e.Request != "" {
+				sb.WriteString("Request:\n\n")
+				writeCodeBlock(&sb, e.Request)
+			}
+			if e.Response != "" {
+				sb.WriteString(strings.TrimSpace("Response "+e.Status) + ":\n\n")
+				writeCodeBlock(&sb, e.Response)
+			}
+		}
+	}
+	for _, t := range req.Types {
+		sb.WriteString(fmt.Sprintf("## %s %s\n\n", t.Kind, t.Name))
+		writeTypeBody(&sb, t)
+	}
+	return strings.TrimRight(sb.String(), "\n") + "\n"
+}
+
+// ConvertTypeToMarkdown renders the Markdown page of a shared type
+func (Usecase) ConvertTypeToMarkdown(doc model.APIDocument, t model.APIType) string {
+	rows := typeRows([]model.APIType{t})
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("# %s\n\n", t.Name))
+	sb.WriteString(fmt.Sprintf("> %s · %s\n\n", doc.Name, t.Kind))
+	writeTypeBody(&sb, rows[0])
+	return strings.TrimRight(sb.String(), "\n") + "\n"
+}
+
+func writeTypeBody(sb *strings.Builder, t model.TypeData) {
+	if t.Description != "" {
+		sb.WriteString(t.Description + "\n\n")
+	}
+	writeFieldTable(sb, t.Fields)
+	if len(t.Values) > 0 {
+		var rows [][]string
+		for _, v := range t.Values {
+			rows = append(rows, []string{fmt.Sprint(v.Number), "**" + v.Field + "**", v.Description})
+		}
+		writeTable(sb, []string{"No.", "Value", "Description"}, rows)
+	}
+}
+
+func writeFieldTable(sb *strings.Builder, fields []model.BodyField) {
+	if len(fields) == 0 {
+		return
+	}
+	var rows [][]string
+	for _, f := range fields {
+		rows = append(rows, []string{fmt.Sprint(f.Number), "**" + f.Field + "**", f.Type, f.Mandatory, f.Description})
+	}
+	writeTable(sb, []string{"No.", "Field", "Type", "Mandatory", "Description"}, rows)
+}
+
+func writeAuthTable(sb *strings.Builder, auth []model.AuthData) {
+	var rows [][]string
+	for _, a := range auth {
+		rows = append(rows, []string{"**" + a.Name + "**", a.Type, a.Location, a.Scopes, a.Description})
+	}
+	writeTable(sb, []string{"Name", "Type", "Location", "Scopes", "Description"}, rows)
+}
+
+func writeTable(sb *strings.Builder, header []string, rows [][]string) {
+	sb.WriteString("| " + strings.Join(header, " | ") + " |\n")
+	sb.WriteString("|" + strings.Repeat(" --- |", len(header)) + "\n")
+	for _, row := range rows {
+		cells := make([]string, len(row))
+		for i, cell := range row {
+			cells[i] = markdownCell(cell)
+		}
+		sb.WriteString("| " + strings.Join(cells, " | ") + " |\n")
+	}
+	sb.WriteString("\n")
+}
+
+// writeCodeBlock fences a body, picking the language from its content and
+// lengthening the fence when the body itself contains backticks
+func writeCodeBlock(sb *strings.Builder, body string) {
+	body = strings.TrimSpace(body)
+	if body == "" {
+		return
+	}
+	lang := bodyLanguage(body)
+	fence := "```"
+	for strings.Contains(body, fence) {
+		fence += "`"
+	}
+	sb.WriteString(fmt.Sprintf("%s%s\n%s\n%s\n\n", fence, lang, body, fence))
+}
+
+// markdownCell keeps a value on one table row
+func markdownCell(s string) string {
+	s = strings.ReplaceAll(strings.TrimSpace(s), "|", `\|`)
+	s = strings.ReplaceAll(s, "\r\n", "\n")
+	return strings.ReplaceAll(s, "\n", "<br>")
+}
+
+// markdownText escapes the characters that would break a link text
+func markdownText(s string) string {
+	return strings.NewReplacer("[", `\[`, "]", `\]`).Replace(s)
+}
+
+func slugify(s string) string {
+	slug := strings.Trim(slugInvalid.ReplaceAllString(strings.ToLower(s), "-"), "-")
+	if slug == "" {
+		return "untitled"
+	}
+	return slug
+}
+
+// pathAllocator hands out unique file paths
+type pathAllocator map[string]bool
+
+func (p pathAllocator) next(dir string, name string, ext string) string {
+	return p.unique(dir+"/"+slugify(name), ext)
+}
+
+func (p pathAllocator) unique(base string, ext string) string {
+	file := base + ext
+	for i := 2; p[file]; i++ {
+		file = fmt.Sprintf("%s-%d%s", base, i, ext)
+	}
+	p[file] = true
+	return file
+}
+
+// MarkdownFiles renders the documents as Markdown files keyed by their path.
+// Several documents each get a folder
+func (Usecase) MarkdownFiles(docs []model.APIDocument, uc *Usecase) map[string][]byte {
+	files := map[string][]byte{}
+	folders := pathAllocator{}
+	for _, doc := range docs {
+		prefix := ""
+		if len(docs) > 1 {
+			prefix = folders.unique(slugify(doc.Name), "") + "/"
+		}
+		for path, content := range uc.RenderMarkdown(doc, uc) {
+			files[prefix+path] = content
+		}
+	}
+	return files
+}
+
+// SendMarkdown renders the documents as Markdown and sends them back to the
+// chat as one zip named after name
+func (Usecase) SendMarkdown(name string, docs []model.APIDocument, uc *Usecase) error {
+	files := uc.MarkdownFiles(docs, uc)
+	caption := fmt.Sprintf("📝 Markdown documentation of %s (%d file(s))", name, len(files))
+	return uc.SendZip(slugify(name)+"-markdown.zip", files, caption, uc)
+}
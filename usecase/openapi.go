@@ -0,0 +1,390 @@
+package usecase
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/arifth/botthie/model"
+)
+
+// openAPIMethods lists the path item keys that hold operations, in the order
+// they are documented
+var openAPIMethods = []string{"get", "put", "post", "delete", "options", "head", "patch", "trace"}
+
+type openAPIImporter struct{}
+
+func (openAPIImporter) Name() string { return "OpenAPI document" }
+
+func (openAPIImporter) Detect(fileName string, data []byte) bool {
+	if !isStructuredDocument(fileName) {
+		return false
+	}
+	doc, err := decodeDocument(data)
+	if err != nil {
+		return false
+	}
+	_, isOpenAPI := doc["openapi"]
+	_, isSwagger := doc["swagger"]
+	return isOpenAPI || isSwagger
+}
+
+func (openAPIImporter) Import(fileName string, data []byte, related map[string][]byte) (model.APIDocument, error) {
+	doc, err := decodeDocument(data)
+	if err != nil {
+		return model.APIDocument{}, fmt.Errorf("invalid OpenAPI document: %w", err)
+	}
+	return ParseOpenAPI(doc)
+}
+
+// ParseOpenAPI maps a decoded Swagger 2.0 or OpenAPI 3.x document to the
+// format-neutral model
+func ParseOpenAPI(doc map[string]interface{}) (model.APIDocument, error) {
+	r := schemaResolver{root: doc}
+	version := firstNonEmpty(scalarStringOf(doc["openapi"]), scalarStringOf(doc["swagger"]))
+	swagger := strings.HasPrefix(version, "2.")
+	info := r.deref(doc["info"])
+	res := model.APIDocument{
+		Name:        firstNonEmpty(stringField(info, "title"), "OpenAPI"),
+		Format:      openAPIImporter{}.Name(),
+		Description: stringField(info, "description"),
+		Version:     stringField(info, "version"),
+	}
+
+	schemes := r.deref(doc["securityDefinitions"])
+	if !swagger {
+		schemes = r.deref(r.deref(doc["components"])["securitySchemes"])
+	}
+	for _, name := range sortedKeys(schemes) {
+		res.Auth = append(res.Auth, openAPIAuth(name, r.deref(schemes[name])))
+	}
+
+	baseURL := openAPIBaseURL(r, doc, swagger)
+	paths := r.deref(doc["paths"])
+	for _, p := range sortedKeys(paths) {
+		item := r.deref(paths[p])
+		if item == nil {
+			continue
+		}
+		shared, _ := item["parameters"].([]interface{})
+		for _, method := range openAPIMethods {
+			op := r.deref(item[method])
+			if op == nil {
+				continue
+			}
+			tag, endpoint := r.openAPIEndpoint(doc, swagger, baseURL+p, method, op, shared)
+			endpoint.Auth = openAPISecurity(res.Auth, firstNonNil(op["security"], doc["security"]))
+			addEndpoint(&res, tag, endpoint)
+		}
+	}
+	if endpointCount(res) == 0 {
+		return res, fmt.Errorf("no paths found: %w", ErrNothingToDocument)
+	}
+	tags, _ := doc["tags"].([]interface{})
+	for _, node := range tags {
+		tag := r.deref(node)
+		for i := range res.Services {
+			if res.Services[i].Name == stringField(tag, "name") {
+				res.Services[i].Description = stringField(tag, "description")
+			}
+		}
+	}
+	for i := range res.Services {
+		res.Services[i].BaseURL = baseURL
+	}
+
+	schemas := r.deref(doc["definitions"])
+	if !swagger {
+		schemas = r.deref(r.deref(doc["components"])["schemas"])
+	}
+	for _, name := range sortedKeys(schemas) {
+		schema := r.deref(schemas[name])
+		if schema == nil {
+			continue
+		}
+		t := model.APIType{
+			Name:        name,
+			Kind:        firstNonEmpty(schemaType(r, schema), "schema"),
+			Description: schemaDescription(schema),
+		}
+		if len(r.properties(schema)) > 0 {
+			t.Fields = bodyFields(r.schemaFields(schema))
+		}
+		values, _ := schema["enum"].([]interface{})
+		for _, v := range values {
+			t.Values = append(t.Values, model.APIField{Name: scalarString(v), Type: t.Kind})
+		}
+		res.Types = append(res.Types, t)
+	}
+	return res, nil
+}
+
+func openAPIBaseURL(r schemaResolver, doc map[string]interface{}, swagger bool) string {
+	if swagger {
+		host := scalarStringOf(doc["host"])
+		if host == "" {
+			return scalarStringOf(doc["basePath"])
+		}
+		scheme := "https"
+		if schemes, ok := doc["schemes"].([]interface{}); ok && len(schemes) > 0 {
+			scheme = fmt.Sprint(schemes[0])
+		}
+		return strings.TrimSuffix(fmt.Sprintf("%s://%s%s", scheme, host, scalarStringOf(doc["basePath"])), "/")
+	}
+	servers, _ := doc["servers"].([]interface{})
+	if len(servers) == 0 {
+		return ""
+	}
+	server := r.deref(servers[0])
+	url := stringField(server, "url")
+	// fill server variables with their defaults
+	variables := r.deref(server["variables"])
+	for _, name := range sortedKeys(variables) {
+		url = strings.ReplaceAll(url, "{"+name+"}", stringField(r.deref(variables[name]), "default"))
+	}
+	return strings.TrimSuffix(url, "/")
+}
+
+func (r schemaResolver) openAPIEndpoint(doc map[string]interface{}, swagger bool, url string, method string, op map[string]interface{}, shared []interface{}) (string, model.APIEndpoint) {
+	endpoint := model.APIEndpoint{
+		Name:        firstNonEmpty(stringField(op, "summary"), stringField(op, "operationId"), strings.ToUpper(method)+" "+url),
+		Kind:        "HTTP",
+		Method:      strings.ToUpper(method),
+		URL:         url,
+		Description: stringField(op, "description"),
+	}
+	tag := ""
+	if tags, ok := op["tags"].([]interface{}); ok && len(tags) > 0 {
+		tag = fmt.Sprint(tags[0])
+	}
+	if deprecated, _ := op["deprecated"].(bool); deprecated {
+		endpoint.Deprecated = "Deprecated"
+	}
+	if id := stringField(op, "operationId"); id != "" {
+		endpoint.Attributes = append(endpoint.Attributes, model.APIAttribute{Key: "Operation ID", Value: id})
+	}
+
+	// operation parameters override the path level ones with the same name
+	params := map[string]map[string]interface{}{}
+	var order []string
+	own, _ := op["parameters"].([]interface{})
+	for _, node := range append(append([]interface{}{}, shared...), own...) {
+		param := r.deref(node)
+		if param == nil {
+			continue
+		}
+		key := stringField(param, "in") + ":" + stringField(param, "name")
+		if _, ok := params[key]; !ok {
+			order = append(order, key)
+		}
+		params[key] = param
+	}
+
+	var formData []model.BodyField
+	for _, key := range order {
+		param := params[key]
+		in := stringField(param, "in")
+		schema := param
+		if s := r.deref(param["schema"]); s != nil {
+			schema = s
+		}
+		required, _ := param["required"].(bool)
+		switch {
+		case swagger && in == "body":
+			endpoint.Body = &model.APIBody{
+				MediaType: openAPIMediaType(doc, op, "consumes"),
+				Fields:    bodyFields(r.schemaFields(schema)),
+			}
+			continue
+		case swagger && in == "formData":
+			formData = append(formData, model.BodyField{
+				Field:       stringField(param, "name"),
+				Type:        schemaType(r, schema),
+				Mandatory:   yesNo(required),
+				Description: stringField(param, "description"),
+			})
+			continue
+		}
+		parameter := model.APIParameter{
+			Name:        stringField(param, "name"),
+			In:          in,
+			Type:        schemaType(r, schema),
+			Required:    required,
+			Default:     scalarStringOf(schema["default"]),
+			Example:     firstNonEmpty(scalarStringOf(param["example"]), scalarStringOf(schema["example"])),
+			Description: schemaDescription(param),
+		}
+		if in == "header" {
+			endpoint.Headers = append(endpoint.Headers, parameter)
+		} else {
+			endpoint.Parameters = append(endpoint.Parameters, parameter)
+		}
+	}
+	if len(formData) > 0 {
+		endpoint.Body = &model.APIBody{
+			MediaType: openAPIMediaType(doc, op, "consumes"),
+			Mode:      "formdata",
+			Fields:    bodyFields(formData),
+		}
+	}
+
+	if body := r.deref(op["requestBody"]); body != nil {
+		var examples []model.APIExample
+		endpoint.Body, examples = r.openAPIContent(body)
+		for _, example := range examples {
+			example.Request, example.Response = example.Response, ""
+			endpoint.Examples = append(endpoint.Examples, example)
+		}
+	}
+
+	responses := r.deref(op["responses"])
+	for _, status := range sortedKeys(responses) {
+		resp := r.deref(responses[status])
+		if resp == nil {
+			continue
+		}
+		response := model.APIResponse{
+			Status:      status,
+			Description: stringField(resp, "description"),
+		}
+		headers := r.deref(resp["headers"])
+		for _, name := range sortedKeys(headers) {
+			header := r.deref(headers[name])
+			schema := header
+			if s := r.deref(header["schema"]); s != nil {
+				schema = s
+			}
+			response.Headers = append(response.Headers, model.APIParameter{
+				Name:        name,
+				In:          "header",
+				Type:        schemaType(r, schema),
+				Description: schemaDescription(header),
+			})
+		}
+		var examples []model.APIExample
+		if swagger {
+			if schema := r.deref(resp["schema"]); schema != nil {
+				response.Body = &model.APIBody{
+					MediaType: openAPIMediaType(doc, op, "produces"),
+					Fields:    bodyFields(r.schemaFields(schema)),
+				}
+			}
+			values := r.deref(resp["examples"])
+			for _, mediaType := range sortedKeys(values) {
+				examples = append(examples, model.APIExample{Name: mediaType, Response: scalarString(values[mediaType])})
+			}
+		} else {
+			response.Body, examples = r.openAPIContent(resp)
+		}
+		for _, example := range examples {
+			example.Status = status
+			endpoint.Examples = append(endpoint.Examples, example)
+		}
+		endpoint.Responses = append(endpoint.Responses, response)
+	}
+	return tag, endpoint
+}
+
+// openAPIContent documents the first media type of a 3.x request body or
+// response, preferring JSON. Its examples are returned as responses, the
+// caller moves them for request bodies
+func (r schemaResolver) openAPIContent(obj map[string]interface{}) (*model.APIBody, []model.APIExample) {
+	content := r.deref(obj["content"])
+	if len(content) == 0 {
+		return nil, nil
+	}
+	types := sortedKeys(content)
+	sort.SliceStable(types, func(i, j int) bool {
+		return strings.Contains(types[i], "json") && !strings.Contains(types[j], "json")
+	})
+	media := r.deref(content[types[0]])
+	body := &model.APIBody{
+		MediaType: types[0],
+		Fields:    bodyFields(r.schemaFields(media["schema"])),
+	}
+	var examples []model.APIExample
+	if example, ok := media["example"]; ok {
+		examples = append(examples, model.APIExample{Name: types[0], Response: scalarString(example)})
+	}
+	named := r.deref(media["examples"])
+	for _, name := range sortedKeys(named) {
+		example := r.deref(named[name])
+		if value, ok := example["value"]; ok {
+			examples = append(examples, model.APIExample{
+				Name:     firstNonEmpty(stringField(example, "summary"), name),
+				Response: scalarString(value),
+			})
+		}
+	}
+	return body, examples
+}
+
+// openAPIAuth maps a Swagger security definition or an OpenAPI security scheme
+func openAPIAuth(name string, scheme map[string]interface{}) model.APIAuth {
+	auth := model.APIAuth{
+		Name:        name,
+		Type:        stringField(scheme, "type"),
+		Description: stringField(scheme, "description"),
+	}
+	switch auth.Type {
+	case "apiKey":
+		auth.In, auth.Key = stringField(scheme, "in"), stringField(scheme, "name")
+	case "http":
+		auth.Type = stringField(scheme, "scheme")
+		auth.In, auth.Key = "header", "Authorization"
+	case "basic":
+		auth.In, auth.Key = "header", "Authorization"
+	case "oauth2", "openIdConnect":
+		auth.In, auth.Key = "header", "Authorization"
+	}
+	return auth
+}
+
+// openAPISecurity resolves a list of security requirements against the
+// schemes of the document. An empty list means the endpoint is public
+func openAPISecurity(schemes []model.APIAuth, requirements interface{}) []model.APIAuth {
+	list, _ := requirements.([]interface{})
+	var res []model.APIAuth
+	for _, node := range list {
+		requirement, _ := node.(map[string]interface{})
+		for _, name := range sortedKeys(requirement) {
+			for _, scheme := range schemes {
+				if scheme.Name != name {
+					continue
+				}
+				scopes, _ := requirement[name].([]interface{})
+				for _, scope := range scopes {
+					scheme.Scopes = append(scheme.Scopes, fmt.Sprint(scope))
+				}
+				res = append(res, scheme)
+			}
+		}
+	}
+	return res
+}
+
+func firstNonNil(values ...interface{}) interface{} {
+	for _, v := range values {
+		if v != nil {
+			return v
+		}
+	}
+	return nil
+}
+
+func openAPIMediaType(doc map[string]interface{}, op map[string]interface{}, key string) string {
+	for _, node := range []interface{}{op[key], doc[key]} {
+		if list, ok := node.([]interface{}); ok && len(list) > 0 {
+			return fmt.Sprint(list[0])
+		}
+	}
+	return ""
+}
+
+// scalarStringOf formats an optional decoded value, returning "" when absent
+func scalarStringOf(value interface{}) string {
+	if value == nil {
+		return ""
+	}
+	return scalarString(value)
+}
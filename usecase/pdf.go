@@ -0,0 +1,426 @@
+package usecase
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/arifth/botthie/model"
+	"github.com/go-pdf/fpdf"
+)
+
+const (
+	pdfMargin      = 15.0
+	pdfLineHeight  = 4.5
+	pdfTOCLineSize = 6.0
+	// pdfMaxCellLines and pdfMaxCodeLines keep a single table row or body
+	// from running over several pages
+	pdfMaxCellLines = 40
+	pdfMaxCodeLines = 150
+)
+
+// pdfWriter renders API documents into an fpdf document, collecting the table
+// of contents while it goes
+type pdfWriter struct {
+	pdf   *fpdf.Fpdf
+	tr    func(string) string
+	width float64
+	toc   []pdfTOCEntry
+}
+
+type pdfTOCEntry struct {
+	level int
+	text  string
+	link  int
+	page  int
+}
+
+// RenderPDF renders the documents as a single PDF API book: a cover page, a
+// table of contents, one chapter per service and one section per endpoint
+func (Usecase) RenderPDF(title string, docs []model.APIDocument, uc *Usecase) ([]byte, error) {
+	pdf := fpdf.New("P", "mm", "A4", "")
+	pdf.SetMargins(pdfMargin, pdfMargin, pdfMargin)
+	pdf.SetAutoPageBreak(true, pdfMargin+5)
+	pdf.SetTitle(title, true)
+	pageWidth, pageHeight := pdf.GetPageSize()
+	w := &pdfWriter{
+		pdf:   pdf,
+		tr:    pdf.UnicodeTranslatorFromDescriptor(""),
+		width: pageWidth - 2*pdfMargin,
+	}
+	pdf.SetFooterFunc(func() {
+		if pdf.PageNo() == 1 {
+			return
+		}
+		pdf.SetY(-pdfMargin)
+		pdf.SetFont("Helvetica", "I", 8)
+		pdf.SetTextColor(120, 120, 120)
+		pdf.CellFormat(0, 5, w.tr(fmt.Sprintf("%s - page %d", title, pdf.PageNo())), "", 0, "C", false, 0, "")
+		pdf.SetTextColor(0, 0, 0)
+	})
+
+	w.cover(title, docs)
+
+	// reserve the table of contents pages, they are filled in once every
+	// chapter knows its page
+	entries := 0
+	for _, doc := range docs {
+		entries += 2 + len(doc.Services) + endpointCount(doc) + len(doc.Types)
+	}
+	perPage := int((pageHeight - 2*pdfMargin - 25) / pdfTOCLineSize)
+	tocPages := int(math.Ceil(float64(entries) / float64(perPage)))
+	firstTOCPage := pdf.PageCount() + 1
+	for i := 0; i < tocPages; i++ {
+		pdf.AddPage()
+	}
+
+	for _, doc := range docs {
+		w.document(doc, len(docs) > 1)
+	}
+	w.tableOfContents(firstTOCPage, perPage)
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (w *pdfWriter) cover(title string, docs []model.APIDocument) {
+	pdf := w.pdf
+	pdf.AddPage()
+	pdf.SetY(90)
+	pdf.SetFont("Helvetica", "B", 28)
+	pdf.MultiCell(0, 12, w.tr(title), "", "C", false)
+	pdf.Ln(4)
+	pdf.SetFont("Helvetica", "", 14)
+	pdf.CellFormat(0, 8, "API Book", "", 1, "C", false, 0, "")
+	pdf.Ln(10)
+	pdf.SetFont("Helvetica", "", 11)
+	for _, doc := range docs {
+		line := doc.Name
+		if doc.Format != "" {
+			line += " - " + doc.Format
+		}
+		if doc.Version != "" {
+			line += " " + doc.Version
+		}
+		pdf.MultiCell(0, 6, w.tr(line), "", "C", false)
+	}
+	if len(docs) == 1 && docs[0].Description != "" {
+		pdf.Ln(6)
+		pdf.SetFont("Helvetica", "I", 10)
+		pdf.MultiCell(0, 5, w.tr(docs[0].Description), "", "C", false)
+	}
+	pdf.SetY(-40)
+	pdf.SetFont("Helvetica", "", 9)
+	pdf.CellFormat(0, 5, "Generated "+time.Now().Format("2 January 2006"), "", 1, "C", false, 0, "")
+}
+
+func (w *pdfWriter) tableOfContents(firstPage int, perPage int) {
+	pdf := w.pdf
+	for i, entry := range w.toc {
+		if i%perPage == 0 {
+			pdf.SetPage(firstPage + i/perPage)
+			pdf.SetY(pdfMargin)
+			if i == 0 {
+				pdf.SetFont("Helvetica", "B", 18)
+				pdf.CellFormat(0, 12, "Table of Contents", "", 1, "L", false, 0, "")
+				pdf.Ln(4)
+			}
+		}
+		indent := float64(entry.level) * 6
+		style := ""
+		if entry.level == 0 {
+			style = "B"
+		}
+		pdf.SetFont("Helvetica", style, 10)
+		pdf.SetX(pdfMargin + indent)
+		page := fmt.Sprint(entry.page)
+		text := w.fit(entry.text, w.width-indent-15)
+		pdf.CellFormat(w.width-indent-15, pdfTOCLineSize, text, "", 0, "L", false, entry.link, "")
+		pdf.CellFormat(15, pdfTOCLineSize, page, "", 1, "R", false, entry.link, "")
+	}
+}
+
+// fit shortens a line to the available width
+func (w *pdfWriter) fit(text string, width float64) string {
+	text = w.tr(text)
+	if w.pdf.GetStringWidth(text) <= width {
+		return text
+	}
+	for len(text) > 0 && w.pdf.GetStringWidth(text+"...") > width {
+		text = text[:len(text)-1]
+	}
+	return text + "..."
+}
+
+// heading starts a linked, bookmarked section
+func (w *pdfWriter) heading(level int, text string, size float64) {
+	pdf := w.pdf
+	// keep a heading together with the first lines of its section
+	if _, pageHeight := pdf.GetPageSize(); pdf.GetY() > pageHeight-pdfMargin-40 {
+		pdf.AddPage()
+	}
+	link := pdf.AddLink()
+	pdf.SetLink(link, pdf.GetY(), pdf.PageNo())
+	pdf.Bookmark(w.tr(text), level, -1)
+	w.toc = append(w.toc, pdfTOCEntry{level: level, text: text, link: link, page: pdf.PageNo()})
+	pdf.SetFont("Helvetica", "B", size)
+	pdf.MultiCell(0, size/2+2, w.tr(text), "", "L", false)
+	pdf.Ln(2)
+}
+
+func (w *pdfWriter) subheading(text string) {
+	w.pdf.Ln(2)
+	w.pdf.SetFont("Helvetica", "B", 11)
+	w.pdf.MultiCell(0, 6, w.tr(text), "", "L", false)
+	w.pdf.Ln(1)
+}
+
+func (w *pdfWriter) paragraph(text string) {
+	if text == "" {
+		return
+	}
+	w.pdf.SetFont("Helvetica", "", 10)
+	w.pdf.MultiCell(0, 5, w.tr(text), "", "L", false)
+	w.pdf.Ln(2)
+}
+
+func (w *pdfWriter) document(doc model.APIDocument, part bool) {
+	pdf := w.pdf
+	level := 0
+	if part {
+		pdf.AddPage()
+		w.heading(0, doc.Name, 22)
+		w.paragraph(doc.Description)
+		level = 1
+	}
+	if len(doc.Auth) > 0 {
+		if !part {
+			pdf.AddPage()
+			w.heading(0, "Authentication", 18)
+		} else {
+			w.subheading("Authentication")
+		}
+		w.authTable(authRows(doc.Auth))
+	}
+
+	for i, service := range doc.Services {
+		pdf.AddPage()
+		chapter := fmt.Sprintf("%d. %s", i+1, firstNonEmpty(service.Name, "Endpoints"))
+		w.heading(level, chapter, 18)
+		w.paragraph(service.Description)
+		if service.BaseURL != "" {
+			w.paragraph("Base URL: " + service.BaseURL)
+		}
+		for j, endpoint := range service.Endpoints {
+			if j > 0 {
+				pdf.Ln(6)
+			}
+			w.endpoint(fmt.Sprintf("%d.%d %s", i+1, j+1, endpoint.Name), level+1, requestData(service, endpoint))
+		}
+	}
+
+	if len(doc.Types) > 0 {
+		pdf.AddPage()
+		w.heading(level, "Types", 18)
+		for _, t := range typeRows(doc.Types) {
+			w.heading(level+1, fmt.Sprintf("%s %s", t.Kind, t.Name), 13)
+			w.typeBody(t)
+		}
+	}
+}
+
+func (w *pdfWriter) endpoint(title string, level int, req model.RequestData) {
+	pdf := w.pdf
+	w.heading(level, title, 14)
+
+	pdf.SetFont("Courier", "B", 10)
+	pdf.SetFillColor(235, 236, 240)
+	pdf.MultiCell(0, 6, w.tr(strings.TrimSpace(req.Method+" "+req.URL)), "", "L", true)
+	pdf.Ln(2)
+	w.paragraph(req.Description)
+	if req.Deprecated != "" {
+		w.paragraph("Deprecated: " + req.Deprecated)
+	}
+
+	if len(req.Attributes) > 0 {
+		var rows [][]string
+		for _, a := range req.Attributes {
+			rows = append(rows, []string{a.Key, a.Value})
+		}
+		w.table([]string{"Key", "Value"}, []float64{0.3, 0.7}, rows)
+	}
+	if len(req.Auth) > 0 {
+		w.subheading("Authentication")
+		w.authTable(req.Auth)
+	}
+	if len(req.Headers) > 0 {
+		w.subheading("Headers")
+		var rows [][]string
+		for _, h := range req.Headers {
+			rows = append(rows, []string{h.Key, h.Value})
+		}
+		w.table([]string{"Key", "Value"}, []float64{0.35, 0.65}, rows)
+	}
+	if len(req.Parameters) > 0 {
+		w.subheading("Parameters")
+		var rows [][]string
+		for _, p := range req.Parameters {
+			rows = append(rows, []string{fmt.Sprint(p.Number), p.Name, p.In, p.Type, p.Mandatory, p.Default, p.Description})
+		}
+		w.table([]string{"No.", "Name", "In", "Type", "Mandatory", "Default", "Description"}, []float64{0.06, 0.18, 0.1, 0.13, 0.11, 0.12, 0.3}, rows)
+	}
+	if len(req.BodyFields) > 0 || req.Body != "" {
+		w.subheading("Request Body")
+		w.fieldTable(req.BodyFields)
+		w.code(req.Body)
+	}
+	for _, r := range req.Responses {
+		w.subheading("Response " + r.Status)
+		w.paragraph(r.Description)
+		if len(r.Headers) > 0 {
+			var rows [][]string
+			for _, h := range r.Headers {
+				rows = append(rows, []string{fmt.Sprint(h.Number), h.Name, h.Type, h.Description})
+			}
+			w.table([]string{"No.", "Header", "Type", "Description"}, []float64{0.06, 0.3, 0.2, 0.44}, rows)
+		}
+		w.fieldTable(r.BodyFields)
+		w.code(r.Body)
+	}
+	for _, e := range req.Examples {
+		w.subheading("Example: " + firstNonEmpty(e.Name, "sample"))
+		if e.Request != "" {
+			w.paragraph("Request")
+			w.code(e.Request)
+		}
+		if e.Response != "" {
+			w.paragraph(strings.TrimSpace("Response " + e.Status))
+			w.code(e.Response)
+		}
+	}
+	for _, t := range req.Types {
+		w.subheading(fmt.Sprintf("%s %s", t.Kind, t.Name))
+		w.typeBody(t)
+	}
+}
+
+func (w *pdfWriter) typeBody(t model.TypeData) {
+	w.paragraph(t.Description)
+	w.fieldTable(t.Fields)
+	if len(t.Values) > 0 {
+		var rows [][]string
+		for _, v := range t.Values {
+			rows = append(rows, []string{fmt.Sprint(v.Number), v.Field, v.Description})
+		}
+		w.table([]string{"No.", "Value", "Description"}, []float64{0.06, 0.34, 0.6}, rows)
+	}
+}
+
+func (w *pdfWriter) fieldTable(fields []model.BodyField) {
+	if len(fields) == 0 {
+		return
+	}
+	var rows [][]string
+	for _, f := range fields {
+		rows = append(rows, []string{fmt.Sprint(f.Number), f.Field, f.Type, f.Mandatory, f.Description})
+	}
+	w.table([]string{"No.", "Field", "Type", "Mandatory", "Description"}, []float64{0.06, 0.26, 0.16, 0.12, 0.4}, rows)
+}
+
+func (w *pdfWriter) authTable(auth []model.AuthData) {
+	var rows [][]string
+	for _, a := range auth {
+		rows = append(rows, []string{a.Name, a.Type, a.Location, a.Scopes, a.Description})
+	}
+	w.table([]string{"Name", "Type", "Location", "Scopes", "Description"}, []float64{0.16, 0.14, 0.22, 0.16, 0.32}, rows)
+}
+
+// table draws a bordered table whose cells wrap, repeating the header row
+// after every page break. widths are fractions of the printable width
+func (w *pdfWriter) table(header []string, widths []float64, rows [][]string) {
+	pdf := w.pdf
+	_, pageHeight := pdf.GetPageSize()
+	limit := pageHeight - pdfMargin - 5
+
+	cols := make([]float64, len(widths))
+	for i, f := range widths {
+		cols[i] = f * w.width
+	}
+	var drawRow func(cells []string, bold bool)
+	drawRow = func(cells []string, bold bool) {
+		style := ""
+		if bold {
+			style = "B"
+		}
+		pdf.SetFont("Helvetica", style, 8)
+		lines := make([][]string, len(cells))
+		height := 0.0
+		for i, cell := range cells {
+			lines[i] = pdf.SplitText(w.tr(cell), cols[i]-2)
+			if len(lines[i]) > pdfMaxCellLines {
+				lines[i] = append(lines[i][:pdfMaxCellLines-1], "...")
+			}
+			height = math.Max(height, float64(len(lines[i]))*pdfLineHeight+2)
+		}
+		height = math.Max(height, pdfLineHeight+2)
+		if pdf.GetY()+height > limit {
+			pdf.AddPage()
+			if !bold {
+				drawRow(header, true)
+				pdf.SetFont("Helvetica", style, 8)
+			}
+		}
+		x, y := pdfMargin, pdf.GetY()
+		for i := range cells {
+			if bold {
+				pdf.SetFillColor(235, 236, 240)
+				pdf.Rect(x, y, cols[i], height, "FD")
+			} else {
+				pdf.Rect(x, y, cols[i], height, "D")
+			}
+			for j, line := range lines[i] {
+				pdf.Text(x+1, y+1+float64(j+1)*pdfLineHeight-1, line)
+			}
+			x += cols[i]
+		}
+		pdf.SetXY(pdfMargin, y+height)
+	}
+
+	drawRow(header, true)
+	for _, row := range rows {
+		drawRow(row, false)
+	}
+	pdf.Ln(3)
+}
+
+// code prints a body in a monospaced block
+func (w *pdfWriter) code(body string) {
+	body = strings.TrimSpace(body)
+	if body == "" {
+		return
+	}
+	lines := strings.Split(strings.ReplaceAll(body, "\t", "    "), "\n")
+	if len(lines) > pdfMaxCodeLines {
+		lines = append(lines[:pdfMaxCodeLines], "...")
+	}
+	w.pdf.SetFont("Courier", "", 8)
+	w.pdf.SetFillColor(244, 245, 247)
+	w.pdf.MultiCell(0, 4, w.tr(strings.Join(lines, "\n")), "", "L", true)
+	w.pdf.Ln(3)
+}
+
+// SendPDF renders the documents as one PDF API book and sends it back to the
+// chat as a document named after name
+func (Usecase) SendPDF(name string, docs []model.APIDocument, uc *Usecase) error {
+	data, err := uc.RenderPDF(name, docs, uc)
+	if err != nil {
+		return err
+	}
+	caption := fmt.Sprintf("📕 API book of %s", name)
+	return uc.SendFile(slugify(name)+".pdf", data, caption, uc)
+}
@@ -0,0 +1,311 @@
+package usecase
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/arifth/botthie/model"
+	"github.com/arifth/botthie/util"
+)
+
+// ErrInvalidCollection is returned for collections that do not follow the
+// supported template
+var ErrInvalidCollection = errors.New("collection Postman tidak valid")
+
+type postmanImporter struct{}
+
+func (postmanImporter) Name() string { return "Postman collection" }
+
+func (postmanImporter) Detect(fileName string, data []byte) bool {
+	if !hasExt(fileName, ".json") {
+		return false
+	}
+	var probe struct {
+		Info *struct {
+			Schema string `json:"schema"`
+		} `json:"info"`
+		Item json.RawMessage `json:"item"`
+	}
+	if err := json.Unmarshal(data, &probe); err != nil {
+		return false
+	}
+	return probe.Info != nil && (probe.Item != nil || strings.Contains(probe.Info.Schema, "getpostman"))
+}
+
+func (postmanImporter) Import(fileName string, data []byte, related map[string][]byte) (model.APIDocument, error) {
+	collection, err := ParsePostmanCollection(data, related)
+	if err != nil {
+		return model.APIDocument{}, err
+	}
+	return postmanToDocument(collection), nil
+}
+
+// ParsePostmanCollection parses and validates a collection after applying the
+// Postman environments found among the related files
+func ParsePostmanCollection(data []byte, related map[string][]byte) (model.PostmanCollection, error) {
+	var collection model.PostmanCollection
+	err := json.Unmarshal(ApplyEnvironment(data, postmanEnvironments(related)), &collection)
+	if err != nil {
+		return collection, fmt.Errorf("failed to parse Postman collection: %w", err)
+	}
+	if !util.Validate(collection) {
+		return collection, ErrInvalidCollection
+	}
+	return collection, nil
+}
+
+// postmanEnvironments returns the Postman environments among the related
+// files in name order
+func postmanEnvironments(related map[string][]byte) []model.PostmanEnvironment {
+	var envs []model.PostmanEnvironment
+	var names []string
+	for name := range related {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if !hasExt(name, ".json") || !IsPostmanEnvironment(related[name]) {
+			continue
+		}
+		var env model.PostmanEnvironment
+		if err := json.Unmarshal(related[name], &env); err == nil {
+			envs = append(envs, env)
+		}
+	}
+	return envs
+}
+
+// IsPostmanEnvironment reports whether data is an exported Postman environment
+func IsPostmanEnvironment(data []byte) bool {
+	var probe struct {
+		Scope  string          `json:"_postman_variable_scope"`
+		Values json.RawMessage `json:"values"`
+		Item   json.RawMessage `json:"item"`
+	}
+	if err := json.Unmarshal(data, &probe); err != nil {
+		return false
+	}
+	return probe.Scope == "environment" || (probe.Values != nil && probe.Item == nil)
+}
+
+// ApplyEnvironment replaces {{variable}} placeholders of a raw collection with
+// the enabled values of the environments
+func ApplyEnvironment(data []byte, envs []model.PostmanEnvironment) []byte {
+	res := string(data)
+	for _, env := range envs {
+		for _, v := range env.Values {
+			if v.Key == "" || (v.Enabled != nil && !*v.Enabled) {
+				continue
+			}
+			// the value lands inside a JSON string, so escape it as one
+			quoted, _ := json.Marshal(v.Value)
+			res = strings.ReplaceAll(res, "{{"+v.Key+"}}", string(quoted[1:len(quoted)-1]))
+		}
+	}
+	return []byte(res)
+}
+
+// postmanRequest is a request of a collection with the folders it is in,
+// the outermost first
+type postmanRequest struct {
+	model.PostmanItem
+	Folders []model.PostmanItem
+}
+
+// postmanRequests returns the requests of a collection in order, descending
+// into its folders
+func postmanRequests(items []model.PostmanItem, folders []model.PostmanItem) []postmanRequest {
+	var requests []postmanRequest
+	for _, item := range items {
+		if item.Item != nil {
+			inner := append(append([]model.PostmanItem{}, folders...), item)
+			requests = append(requests, postmanRequests(item.Item, inner)...)
+			continue
+		}
+		requests = append(requests, postmanRequest{PostmanItem: item, Folders: folders})
+	}
+	return requests
+}
+
+// Service names the folders of a request, e.g. "Users / Admin"; requests at
+// the top of the collection have none
+func (r postmanRequest) Service() string {
+	var names []string
+	for _, f := range r.Folders {
+		names = append(names, f.Name)
+	}
+	return strings.Join(names, " / ")
+}
+
+// Auth returns the auth of the request, or of its innermost folder with
+// one; nil inherits the collection auth
+func (r postmanRequest) Auth() *model.PostmanAuth {
+	if r.Request.Auth != nil {
+		return r.Request.Auth
+	}
+	for i := len(r.Folders) - 1; i >= 0; i-- {
+		if r.Folders[i].Auth != nil {
+			return r.Folders[i].Auth
+		}
+	}
+	return nil
+}
+
+func postmanToDocument(collection model.PostmanCollection) model.APIDocument {
+	doc := model.APIDocument{
+		Name:        collection.Info.Name,
+		ID:          collection.Info.PostmanID,
+		Format:      postmanImporter{}.Name(),
+		Description: postmanDescription(collection.Info.Description),
+		Auth:        postmanAuth(collection.Auth),
+	}
+	// every folder becomes a service, requests at the top of the collection
+	// go to an unnamed one
+	for _, item := range postmanRequests(collection.Item, nil) {
+		endpoint := model.APIEndpoint{
+			Name:        item.Name,
+			Kind:        "HTTP",
+			Method:      item.Request.Method,
+			URL:         extractURL(item.Request),
+			Description: postmanDescription(item.Request.Description),
+			Auth:        doc.Auth,
+		}
+		// a request without its own auth inherits the one of its folders or
+		// the collection
+		if auth := item.Auth(); auth != nil {
+			endpoint.Auth = postmanAuth(auth)
+		}
+		for _, h := range item.Request.Header {
+			endpoint.Headers = append(endpoint.Headers, model.APIParameter{
+				Name:    h.Key,
+				In:      "header",
+				Type:    h.Type,
+				Example: h.Value,
+			})
+		}
+		endpoint.Body = postmanBody(item.Request.Body)
+		for _, r := range item.Response {
+			example := model.APIExample{
+				Name:     r.Name,
+				Status:   strings.TrimSpace(fmt.Sprintf("%d %s", r.Code, r.Status)),
+				Response: r.Body,
+			}
+			if r.OriginalRequest != nil && r.OriginalRequest.Body != nil {
+				example.Request = r.OriginalRequest.Body.Raw
+			}
+			endpoint.Examples = append(endpoint.Examples, example)
+		}
+		addEndpoint(&doc, item.Service(), endpoint)
+		if len(item.Folders) > 0 {
+			folder := item.Folders[len(item.Folders)-1]
+			for i := range doc.Services {
+				if doc.Services[i].Name == item.Service() && doc.Services[i].Description == "" {
+					doc.Services[i].Description = postmanDescription(folder.Description)
+				}
+			}
+		}
+	}
+	return doc
+}
+
+// postmanEndpoints returns, for every request of a collection in order, the
+// index of its service and endpoint in the document postmanToDocument builds
+func postmanEndpoints(collection model.PostmanCollection) [][2]int {
+	services := map[string]int{}
+	counts := map[string]int{}
+	var positions [][2]int
+	for _, r := range postmanRequests(collection.Item, nil) {
+		name := r.Service()
+		if _, ok := services[name]; !ok {
+			services[name] = len(services)
+		}
+		positions = append(positions, [2]int{services[name], counts[name]})
+		counts[name]++
+	}
+	return positions
+}
+
+// postmanAuth maps the auth of a collection or request; noauth yields none
+func postmanAuth(auth *model.PostmanAuth) []model.APIAuth {
+	if auth == nil || auth.Type == "" || auth.Type == "noauth" {
+		return nil
+	}
+	param := func(params []model.PostmanAuthParam, key string) string {
+		for _, p := range params {
+			if p.Key == key {
+				return scalarStringOf(p.Value)
+			}
+		}
+		return ""
+	}
+	res := model.APIAuth{Name: auth.Type, Type: auth.Type, In: "header", Key: "Authorization"}
+	switch auth.Type {
+	case "bearer":
+		res.Description = "Bearer token in the Authorization header"
+	case "basic":
+		res.Description = "Username and password in the Authorization header"
+	case "apikey":
+		res.Key = param(auth.APIKey, "key")
+		res.In = firstNonEmpty(param(auth.APIKey, "in"), "header")
+		res.Description = fmt.Sprintf("API key sent as the %s %s", res.Key, res.In)
+	case "oauth2":
+		res.Description = "OAuth 2.0 access token"
+		if scope := param(auth.OAuth2, "scope"); scope != "" {
+			res.Scopes = strings.Fields(scope)
+		}
+	default:
+		res.In, res.Key = "", ""
+	}
+	return []model.APIAuth{res}
+}
+
+// postmanDescription reads a description given either as plain text or as a
+// {content, type} object
+func postmanDescription(description interface{}) string {
+	switch v := description.(type) {
+	case string:
+		return v
+	case map[string]interface{}:
+		content, _ := v["content"].(string)
+		return content
+	default:
+		return ""
+	}
+}
+
+func postmanBody(body *model.PostmanBody) *model.APIBody {
+	if body == nil {
+		return nil
+	}
+	res := &model.APIBody{Mode: body.Mode}
+
+	// Parse body based on mode
+	switch {
+	case body.Mode == "raw" && body.Raw != "":
+		res.Raw = body.Raw
+		// Try to parse as JSON to extract fields
+		res.Fields = parseJSONBodyFields(body.Raw)
+	case body.Mode == "formdata" && len(body.FormData) > 0:
+		res.Fields = formFields(body.FormData)
+	case body.Mode == "urlencoded" && len(body.URLEncoded) > 0:
+		res.Fields = formFields(body.URLEncoded)
+	case body.Raw != "":
+		res.Raw = body.Raw
+	}
+	return res
+}
+
+func formFields(items []model.PostmanFormDataItem) []model.APIField {
+	var fields []model.APIField
+	for _, field := range items {
+		fields = append(fields, model.APIField{
+			Name:        field.Key,
+			Type:        determineType(field.Value),
+			Description: makeReadable(field.Key),
+		})
+	}
+	return fields
+}
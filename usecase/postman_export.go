@@ -0,0 +1,194 @@
+package usecase
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/arifth/botthie/model"
+)
+
+// postmanSchema is the schema of the exported collections
+const postmanSchema = "https://schema.getpostman.com/json/collection/v2.1.0/collection.json"
+
+// postmanGenerated separates the original description of a request from the
+// generated documentation, so that exporting a collection again replaces it
+const postmanGenerated = "<!-- generated documentation -->"
+
+// ExportPostmanCollection returns the collection as Postman v2.1 with the
+// generated documentation injected back into its items: the descriptions
+// extended with the field tables, the inferred types of form fields, the
+// language of raw bodies and the examples completed with their request.
+// When edits is given, the descriptions and mandatory flags reviewers
+// corrected on the published pages of the collection replace the generated
+// ones. Everything else of the original items, {{variables}} included, is
+// kept as is
+func ExportPostmanCollection(data []byte, related map[string][]byte, edits ReviewedEdits) (string, []byte, error) {
+	// environments are not applied to the export, their values, often
+	// secrets, would end up in the items and the field tables. The
+	// resolved collection is what was published, to tell the reviewed
+	// values apart
+	collection, err := ParsePostmanCollection(data, nil)
+	if err != nil {
+		return "", nil, err
+	}
+	resolved, err := ParsePostmanCollection(data, related)
+	if err != nil {
+		return "", nil, err
+	}
+	var raw map[string]interface{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return "", nil, fmt.Errorf("failed to parse Postman collection: %w", err)
+	}
+
+	if info, ok := raw["info"].(map[string]interface{}); ok {
+		info["schema"] = postmanSchema
+		// re-importing creates a new collection instead of replacing the
+		// original
+		delete(info, "_postman_id")
+	}
+	doc, positions := postmanToDocument(collection), postmanEndpoints(collection)
+	published, publishedPositions := postmanToDocument(resolved), postmanEndpoints(resolved)
+	var reviewed map[string]PageEdits
+	if edits != nil {
+		if reviewed, err = edits(published); err != nil {
+			return "", nil, fmt.Errorf("failed to read the reviewed pages of %s: %w", published.Name, err)
+		}
+	}
+	pageKeys := endpointPageKeys(published)
+	// the raw items are walked in the order of postmanRequests
+	for i, item := range rawPostmanRequests(raw["item"]) {
+		request, isRequest := item["request"].(map[string]interface{})
+		if !isRequest || i >= len(positions) {
+			continue
+		}
+		pos, publishedPos := positions[i], publishedPositions[i]
+		req := requestData(doc.Services[pos[0]], doc.Services[pos[0]].Endpoints[pos[1]])
+		if e, ok := reviewed[pageKeys[publishedPos[0]][publishedPos[1]]]; ok {
+			service := published.Services[publishedPos[0]]
+			e.apply(&req, requestData(service, service.Endpoints[publishedPos[1]]))
+		}
+		request["description"] = postmanRequestDescription(req)
+		enrichPostmanBody(request, req.BodyFields)
+		responses, _ := item["response"].([]interface{})
+		for _, r := range responses {
+			if response, ok := r.(map[string]interface{}); ok {
+				enrichPostmanResponse(response, request)
+			}
+		}
+	}
+
+	// keep the marker readable in the description
+	var out bytes.Buffer
+	encoder := json.NewEncoder(&out)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "\t")
+	if err := encoder.Encode(raw); err != nil {
+		return "", nil, err
+	}
+	return collection.Info.Name, out.Bytes(), nil
+}
+
+// rawPostmanRequests returns the request items of a decoded collection in
+// order, descending into its folders like postmanRequests
+func rawPostmanRequests(value interface{}) []map[string]interface{} {
+	items, _ := value.([]interface{})
+	var requests []map[string]interface{}
+	for _, v := range items {
+		item, ok := v.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if children, isFolder := item["item"].([]interface{}); isFolder {
+			requests = append(requests, rawPostmanRequests(children)...)
+			continue
+		}
+		requests = append(requests, item)
+	}
+	return requests
+}
+
+// ExportPostmanCollections exports an uploaded collection, or every
+// collection of a zip archive, with ExportPostmanCollection
+func ExportPostmanCollections(fileName string, data []byte, edits ReviewedEdits) (map[string][]byte, error) {
+	return convertPostmanFiles(fileName, data, ".postman_collection", ".json", func(content []byte, files map[string][]byte) (string, []byte, error) {
+		return ExportPostmanCollection(content, files, edits)
+	})
+}
+
+// postmanRequestDescription renders the Markdown description of a request:
+// its own description followed by the generated documentation
+func postmanRequestDescription(req model.RequestData) string {
+	var sb strings.Builder
+	if description, _, _ := strings.Cut(req.Description, postmanGenerated); strings.TrimSpace(description) != "" {
+		sb.WriteString(strings.TrimSpace(description) + "\n\n")
+	}
+	sb.WriteString(postmanGenerated + "\n\n")
+	if req.Deprecated != "" {
+		sb.WriteString(fmt.Sprintf("**Deprecated:** %s\n\n", req.Deprecated))
+	}
+	if len(req.Auth) > 0 {
+		sb.WriteString("## Authentication\n\n")
+		writeAuthTable(&sb, req.Auth)
+	}
+	if len(req.BodyFields) > 0 {
+		sb.WriteString("## Request Body\n\n")
+		writeFieldTable(&sb, req.BodyFields)
+	}
+	for _, e := range req.Examples {
+		fields := fieldRows(parseJSONBodyFields(e.Response))
+		if len(fields) == 0 {
+			continue
+		}
+		sb.WriteString(fmt.Sprintf("## Response %s\n\n", firstNonEmpty(e.Status, e.Name)))
+		writeFieldTable(&sb, fields)
+	}
+	return strings.TrimRight(sb.String(), "\n") + "\n"
+}
+
+// enrichPostmanBody describes the form fields with their inferred type and
+// sets the language of raw bodies so Postman highlights them
+func enrichPostmanBody(request map[string]interface{}, fields []model.BodyField) {
+	body, ok := request["body"].(map[string]interface{})
+	if !ok {
+		return
+	}
+	for _, mode := range []string{"formdata", "urlencoded"} {
+		items, _ := body[mode].([]interface{})
+		for _, value := range items {
+			item, ok := value.(map[string]interface{})
+			if !ok || item["description"] != nil {
+				continue
+			}
+			for _, f := range fields {
+				if key, _ := item["key"].(string); key == f.Field {
+					item["description"] = strings.TrimSpace(fmt.Sprintf("%s: %s", f.Type, f.Description))
+					break
+				}
+			}
+		}
+	}
+	raw, _ := body["raw"].(string)
+	if language := bodyLanguage(raw); language != "" && body["options"] == nil {
+		body["options"] = map[string]interface{}{"raw": map[string]interface{}{"language": language}}
+	}
+}
+
+// enrichPostmanResponse completes a saved example with the request it
+// answers and the language of its body
+func enrichPostmanResponse(response map[string]interface{}, request map[string]interface{}) {
+	if response["originalRequest"] == nil {
+		original := map[string]interface{}{}
+		for key, value := range request {
+			if key != "description" {
+				original[key] = value
+			}
+		}
+		response["originalRequest"] = original
+	}
+	body, _ := response["body"].(string)
+	if language := bodyLanguage(body); language != "" && response["_postman_previewlanguage"] == nil {
+		response["_postman_previewlanguage"] = language
+	}
+}
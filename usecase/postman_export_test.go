@@ -0,0 +1,92 @@
+package usecase
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/arifth/botthie/model"
+)
+
+const exportCollection = `{
+	"info": {"_postman_id": "1", "name": "Pet Store"},
+	"item": [{
+		"name": "Pets",
+		"item": [{
+			"name": "Create pet",
+			"request": {
+				"method": "POST",
+				"header": [{"key": "Authorization", "value": "Bearer {{token}}"}],
+				"url": "{{baseUrl}}/pets",
+				"body": {"mode": "raw", "raw": "{\"name\": \"{{petName}}\", \"tag\": \"dog\"}"}
+			}
+		}]
+	}]
+}`
+
+const exportEnvironment = `{
+	"name": "dev",
+	"values": [
+		{"key": "token", "value": "SECRET-123", "enabled": true},
+		{"key": "petName", "value": "Rex", "enabled": true}
+	]
+}`
+
+func TestExportPostmanCollectionMergesReviewedEdits(t *testing.T) {
+	related := map[string][]byte{"dev.postman_environment.json": []byte(exportEnvironment)}
+	var keys []string
+	edits := func(doc model.APIDocument) (map[string]PageEdits, error) {
+		keys = endpointPageKeys(doc)[0]
+		return map[string]PageEdits{keys[0]: {
+			Description: "Adds a pet to the store",
+			Fields: map[string]model.BodyField{
+				"tag": {Field: "tag", Mandatory: "Yes", Description: "Species of the pet"},
+			},
+		}}, nil
+	}
+
+	name, out, err := ExportPostmanCollection([]byte(exportCollection), related, edits)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if name != "Pet Store" || len(keys) != 1 {
+		t.Fatalf("exported %q with page keys %v", name, keys)
+	}
+	if strings.Contains(string(out), "SECRET-123") {
+		t.Errorf("export contains an environment value:\n%s", out)
+	}
+	var exported struct {
+		Item []struct {
+			Item []struct {
+				Request struct {
+					Description string `json:"description"`
+				} `json:"request"`
+			} `json:"item"`
+		} `json:"item"`
+	}
+	if err := json.Unmarshal(out, &exported); err != nil {
+		t.Fatal(err)
+	}
+	description := exported.Item[0].Item[0].Request.Description
+	for _, want := range []string{"Adds a pet to the store", "Species of the pet", "{{token}}"} {
+		if !strings.Contains(description+string(out), want) {
+			t.Errorf("export lacks %q:\n%s", want, description)
+		}
+	}
+}
+
+func TestExportPostmanCollectionReportsEditErrors(t *testing.T) {
+	failing := func(model.APIDocument) (map[string]PageEdits, error) {
+		return nil, errors.New("401 Unauthorized")
+	}
+	_, _, err := ExportPostmanCollection([]byte(exportCollection), nil, failing)
+	if err == nil || !strings.Contains(err.Error(), "Pet Store") || !strings.Contains(err.Error(), "401 Unauthorized") {
+		t.Errorf("error = %v, want the failure to read the pages of Pet Store", err)
+	}
+
+	// without edits nothing is read
+	if _, _, err := ExportPostmanCollection([]byte(exportCollection), nil, nil); err != nil {
+		t.Errorf("export without edits: %v", err)
+	}
+}
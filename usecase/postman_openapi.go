@@ -0,0 +1,552 @@
+package usecase
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/arifth/botthie/model"
+	"github.com/arifth/botthie/util"
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	// postmanPlaceholder matches a {{variable}} left over after applying the
+	// environments
+	postmanPlaceholder = regexp.MustCompile(`{{\s*([\w.-]+)\s*}}`)
+	postmanOrigin      = regexp.MustCompile(`^(?:[a-zA-Z][a-zA-Z0-9+.-]*://[^/?#]*|{{\s*[\w.-]+\s*}})`)
+	operationIDInvalid = regexp.MustCompile(`[^A-Za-z0-9]+`)
+)
+
+// postmanURL is the object form of a request url
+type postmanURL struct {
+	Raw   string `json:"raw"`
+	Query []struct {
+		Key         string      `json:"key"`
+		Value       string      `json:"value"`
+		Description interface{} `json:"description"`
+		Disabled    bool        `json:"disabled"`
+	} `json:"query"`
+	Variable []struct {
+		Key         string      `json:"key"`
+		Value       interface{} `json:"value"`
+		Description interface{} `json:"description"`
+	} `json:"variable"`
+}
+
+// PostmanToOpenAPI converts a collection to an OpenAPI 3.1 document: request
+// urls become templated paths on a shared server, query, path and header
+// values become parameters, bodies get schemas inferred from their samples,
+// saved examples become responses and the auth becomes security schemes
+func PostmanToOpenAPI(collection model.PostmanCollection) model.OpenAPISpec {
+	spec := model.OpenAPISpec{
+		OpenAPI: "3.1.0",
+		Info: model.OpenAPIInfo{
+			Title:       firstNonEmpty(collection.Info.Name, "API"),
+			Description: postmanDescription(collection.Info.Description),
+			Version:     "1.0.0",
+		},
+		Paths: map[string]*model.OpenAPIPathItem{},
+	}
+	schemes := map[string]*model.OpenAPISecurityScheme{}
+	if name, scheme := postmanSecurityScheme(collection.Auth); scheme != nil {
+		schemes[name] = scheme
+		spec.Security = []map[string][]string{{name: postmanScopes(collection.Auth)}}
+	}
+
+	// the most used origin is the server of the document, requests to other
+	// origins name their own server
+	requests := postmanRequests(collection.Item, nil)
+	origins := map[string]int{}
+	for _, item := range requests {
+		origin, _ := splitPostmanURL(postmanRequestURL(item.Request).Raw)
+		origins[origin]++
+	}
+	server := ""
+	for origin, count := range origins {
+		if count > origins[server] || (count == origins[server] && origin < server) {
+			server = origin
+		}
+	}
+	if server != "" {
+		spec.Servers = []model.OpenAPIServer{openAPIServer(server)}
+	}
+
+	operationIDs := map[string]bool{}
+	tags := map[string]bool{}
+	for _, item := range requests {
+		req := item.Request
+		u := postmanRequestURL(req)
+		origin, path := splitPostmanURL(u.Raw)
+		path, pathParams := openAPIPath(path)
+
+		op := &model.OpenAPIOperation{
+			Summary:     item.Name,
+			Description: postmanDescription(req.Description),
+			OperationID: uniqueOperationID(operationIDs, item.Name),
+			Responses:   map[string]*model.OpenAPIResponse{},
+		}
+		if origin != server {
+			op.Servers = []model.OpenAPIServer{openAPIServer(origin)}
+		}
+		// the folders of a request become its tag
+		if tag := item.Service(); tag != "" {
+			op.Tags = []string{tag}
+			if !tags[tag] {
+				tags[tag] = true
+				folder := item.Folders[len(item.Folders)-1]
+				spec.Tags = append(spec.Tags, model.OpenAPITag{Name: tag, Description: postmanDescription(folder.Description)})
+			}
+		}
+
+		for _, name := range pathParams {
+			param := model.OpenAPIParameter{Name: name, In: "path", Required: true, Schema: &model.OpenAPISchema{Type: "string"}}
+			for _, v := range u.Variable {
+				if v.Key == name {
+					param.Description = postmanDescription(v.Description)
+					if value := scalarStringOf(v.Value); value != "" {
+						param.Example = value
+					}
+				}
+			}
+			op.Parameters = append(op.Parameters, param)
+		}
+		op.Parameters = append(op.Parameters, postmanQuery(u)...)
+		contentType := ""
+		for _, h := range req.Header {
+			// OpenAPI describes these headers with the body and security
+			switch strings.ToLower(h.Key) {
+			case "content-type":
+				contentType = h.Value
+				continue
+			case "accept", "authorization":
+				continue
+			}
+			param := model.OpenAPIParameter{Name: h.Key, In: "header", Schema: &model.OpenAPISchema{Type: "string"}}
+			if h.Value != "" {
+				param.Example = h.Value
+			}
+			op.Parameters = append(op.Parameters, param)
+		}
+		op.RequestBody = openAPIRequestBody(req.Body, contentType)
+
+		for _, r := range item.Response {
+			openAPIExampleResponse(op, r)
+		}
+		if len(op.Responses) == 0 {
+			op.Responses["200"] = &model.OpenAPIResponse{Description: "Successful response"}
+		}
+
+		// a request or folder with its own auth overrides the collection auth
+		if auth := item.Auth(); auth != nil {
+			security := []map[string][]string{}
+			if name, scheme := postmanSecurityScheme(auth); scheme != nil {
+				schemes[name] = scheme
+				security = append(security, map[string][]string{name: postmanScopes(auth)})
+			}
+			op.Security = &security
+		}
+
+		if spec.Paths[path] == nil {
+			spec.Paths[path] = &model.OpenAPIPathItem{}
+		}
+		setOperation(spec.Paths[path], req.Method, op)
+	}
+
+	if len(schemes) > 0 {
+		spec.Components = &model.OpenAPIComponents{SecuritySchemes: schemes}
+	}
+	return spec
+}
+
+// MarshalOpenAPI encodes a document as "json" or, by default, YAML
+func MarshalOpenAPI(spec model.OpenAPISpec, format string) ([]byte, error) {
+	if format == "json" {
+		return json.MarshalIndent(spec, "", "  ")
+	}
+	var sb strings.Builder
+	encoder := yaml.NewEncoder(&sb)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(spec); err != nil {
+		return nil, err
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+	return []byte(sb.String()), nil
+}
+
+// ConvertPostmanToOpenAPI converts an uploaded collection, or every collection
+// of a zip archive, to OpenAPI files keyed by their name. Environments next
+// to the collections resolve their variables
+func ConvertPostmanToOpenAPI(fileName string, data []byte, format string) (map[string][]byte, error) {
+	ext := ".yaml"
+	if format == "json" {
+		ext = ".json"
+	}
+	return convertPostmanFiles(fileName, data, "-openapi", ext, func(content []byte, files map[string][]byte) (string, []byte, error) {
+		collection, err := ParsePostmanCollection(content, files)
+		if err != nil {
+			return "", nil, err
+		}
+		out, err := MarshalOpenAPI(PostmanToOpenAPI(collection), format)
+		return collection.Info.Name, out, err
+	})
+}
+
+// convertPostmanFiles converts an uploaded collection, or every collection of
+// a zip, with convert, which receives the other files for their environments
+// and returns the collection name. The results are named after the
+// collections with suffix and ext
+func convertPostmanFiles(fileName string, data []byte, suffix string, ext string, convert func(content []byte, files map[string][]byte) (string, []byte, error)) (map[string][]byte, error) {
+	files := map[string][]byte{fileName: data}
+	if hasExt(fileName, ".zip") {
+		var err error
+		if files, err = util.ReadZip(data); err != nil {
+			return nil, err
+		}
+	}
+
+	res := map[string][]byte{}
+	names := pathAllocator{}
+	for name, content := range files {
+		if !(postmanImporter{}).Detect(name, content) {
+			continue
+		}
+		collection, out, err := convert(content, files)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		res[names.unique(slugify(firstNonEmpty(collection, name))+suffix, ext)] = out
+	}
+	if len(res) == 0 {
+		return nil, fmt.Errorf("no Postman collection in %s: %w", fileName, ErrUnknownFormat)
+	}
+	return res, nil
+}
+
+func postmanRequestURL(req model.PostmanRequest) postmanURL {
+	var u postmanURL
+	if raw, ok := req.URL.(map[string]interface{}); ok {
+		data, _ := json.Marshal(raw)
+		json.Unmarshal(data, &u)
+	}
+	u.Raw = extractURL(req)
+	return u
+}
+
+// splitPostmanURL splits a raw url into its origin, either scheme://host or a
+// leading {{variable}}, and the path without the query
+func splitPostmanURL(raw string) (string, string) {
+	raw = strings.TrimSpace(raw)
+	if idx := strings.IndexAny(raw, "?#"); idx >= 0 {
+		raw = raw[:idx]
+	}
+	origin := postmanOrigin.FindString(raw)
+	return origin, raw[len(origin):]
+}
+
+// openAPIPath templates :param and {{variable}} segments and returns the path
+// parameter names in order
+func openAPIPath(path string) (string, []string) {
+	var params []string
+	var segments []string
+	for _, segment := range strings.Split(strings.Trim(path, "/"), "/") {
+		if segment == "" {
+			continue
+		}
+		name := ""
+		switch {
+		case strings.HasPrefix(segment, ":"):
+			name = segment[1:]
+		case postmanPlaceholder.MatchString(segment) && postmanPlaceholder.FindString(segment) == segment:
+			name = postmanPlaceholder.FindStringSubmatch(segment)[1]
+		}
+		if name != "" {
+			params = append(params, name)
+			segment = "{" + name + "}"
+		}
+		segments = append(segments, segment)
+	}
+	return "/" + strings.Join(segments, "/"), params
+}
+
+// openAPIServer turns an origin into a server, an unresolved {{variable}}
+// becomes a server variable
+func openAPIServer(origin string) model.OpenAPIServer {
+	match := postmanPlaceholder.FindStringSubmatch(origin)
+	if match == nil {
+		return model.OpenAPIServer{URL: origin}
+	}
+	return model.OpenAPIServer{
+		URL: "{" + match[1] + "}",
+		Variables: map[string]model.OpenAPIServerVariable{
+			match[1]: {Default: "http://localhost", Description: fmt.Sprintf("Value of the Postman variable %s", match[1])},
+		},
+	}
+}
+
+func postmanQuery(u postmanURL) []model.OpenAPIParameter {
+	var params []model.OpenAPIParameter
+	if len(u.Query) > 0 {
+		for _, q := range u.Query {
+			if q.Disabled || q.Key == "" {
+				continue
+			}
+			param := model.OpenAPIParameter{
+				Name:        q.Key,
+				In:          "query",
+				Description: postmanDescription(q.Description),
+				Schema:      &model.OpenAPISchema{Type: "string"},
+			}
+			if q.Value != "" {
+				param.Example = q.Value
+			}
+			params = append(params, param)
+		}
+		return params
+	}
+	idx := strings.Index(u.Raw, "?")
+	if idx < 0 {
+		return nil
+	}
+	values, _ := url.ParseQuery(u.Raw[idx+1:])
+	var keys []string
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		param := model.OpenAPIParameter{Name: key, In: "query", Schema: &model.OpenAPISchema{Type: "string"}}
+		if v := values.Get(key); v != "" {
+			param.Example = v
+		}
+		params = append(params, param)
+	}
+	return params
+}
+
+func openAPIRequestBody(body *model.PostmanBody, contentType string) *model.OpenAPIRequestBody {
+	if body == nil {
+		return nil
+	}
+	switch {
+	case body.Mode == "formdata" && len(body.FormData) > 0:
+		return &model.OpenAPIRequestBody{Content: map[string]*model.OpenAPIMediaType{
+			"multipart/form-data": {Schema: formSchema(body.FormData)},
+		}}
+	case body.Mode == "urlencoded" && len(body.URLEncoded) > 0:
+		return &model.OpenAPIRequestBody{Content: map[string]*model.OpenAPIMediaType{
+			"application/x-www-form-urlencoded": {Schema: formSchema(body.URLEncoded)},
+		}}
+	case body.Raw != "":
+		mediaType, media := sampleMedia(body.Raw, contentType)
+		return &model.OpenAPIRequestBody{Content: map[string]*model.OpenAPIMediaType{mediaType: media}}
+	}
+	return nil
+}
+
+func formSchema(items []model.PostmanFormDataItem) *model.OpenAPISchema {
+	schema := &model.OpenAPISchema{Type: "object", Properties: map[string]*model.OpenAPISchema{}}
+	for _, item := range items {
+		prop := &model.OpenAPISchema{Type: "string", Description: makeReadable(item.Key)}
+		if item.Type == "file" {
+			prop.Format = "binary"
+		} else if item.Value != "" {
+			prop.Example = item.Value
+		}
+		schema.Properties[item.Key] = prop
+	}
+	return schema
+}
+
+// sampleMedia describes a sample body, inferring the schema of JSON bodies.
+// The media type comes from the Content-Type header or the body itself
+func sampleMedia(body string, contentType string) (string, *model.OpenAPIMediaType) {
+	mediaType := strings.TrimSpace(strings.Split(contentType, ";")[0])
+	var value interface{}
+	if err := json.Unmarshal([]byte(body), &value); err == nil && (mediaType == "" || strings.Contains(mediaType, "json")) {
+		return firstNonEmpty(mediaType, "application/json"), &model.OpenAPIMediaType{Schema: inferSchema(value), Example: value}
+	}
+	if mediaType == "" {
+		mediaType = http.DetectContentType([]byte(body))
+		if strings.HasPrefix(strings.TrimSpace(body), "<") {
+			mediaType = "application/xml"
+		}
+		mediaType = strings.TrimSpace(strings.Split(mediaType, ";")[0])
+	}
+	return mediaType, &model.OpenAPIMediaType{Schema: &model.OpenAPISchema{Type: "string"}, Example: body}
+}
+
+// inferSchema derives a JSON schema from a sample value. Arrays take the
+// schema of their first item
+func inferSchema(value interface{}) *model.OpenAPISchema {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		schema := &model.OpenAPISchema{Type: "object", Properties: map[string]*model.OpenAPISchema{}}
+		for key, item := range v {
+			schema.Properties[key] = inferSchema(item)
+		}
+		return schema
+	case []interface{}:
+		schema := &model.OpenAPISchema{Type: "array", Items: &model.OpenAPISchema{}}
+		if len(v) > 0 {
+			schema.Items = inferSchema(v[0])
+		}
+		return schema
+	case float64:
+		if v == float64(int64(v)) {
+			return &model.OpenAPISchema{Type: "integer"}
+		}
+		return &model.OpenAPISchema{Type: "number"}
+	case bool:
+		return &model.OpenAPISchema{Type: "boolean"}
+	case string:
+		return &model.OpenAPISchema{Type: "string"}
+	default:
+		return &model.OpenAPISchema{Type: "null"}
+	}
+}
+
+// openAPIExampleResponse adds a saved example to the responses of an
+// operation, examples sharing a status code are listed side by side
+func openAPIExampleResponse(op *model.OpenAPIOperation, r model.PostmanResponse) {
+	code := "default"
+	if r.Code > 0 {
+		code = fmt.Sprint(r.Code)
+	}
+	res := op.Responses[code]
+	if res == nil {
+		res = &model.OpenAPIResponse{Description: firstNonEmpty(r.Status, r.Name, http.StatusText(r.Code), "Response")}
+		op.Responses[code] = res
+	}
+	contentType := ""
+	for _, h := range r.Header {
+		if strings.EqualFold(h.Key, "content-type") {
+			contentType = h.Value
+			continue
+		}
+		if res.Headers == nil {
+			res.Headers = map[string]*model.OpenAPIHeader{}
+		}
+		res.Headers[h.Key] = &model.OpenAPIHeader{Schema: &model.OpenAPISchema{Type: "string"}, Example: h.Value}
+	}
+	if strings.TrimSpace(r.Body) == "" {
+		return
+	}
+	mediaType, media := sampleMedia(r.Body, contentType)
+	if res.Content == nil {
+		res.Content = map[string]*model.OpenAPIMediaType{}
+	}
+	existing := res.Content[mediaType]
+	if existing == nil {
+		existing = &model.OpenAPIMediaType{Schema: media.Schema, Examples: map[string]model.OpenAPIExample{}}
+		res.Content[mediaType] = existing
+	}
+	base := slugify(firstNonEmpty(r.Name, "example"))
+	name := base
+	for i := 2; existing.Examples[name].Value != nil; i++ {
+		name = fmt.Sprintf("%s-%d", base, i)
+	}
+	existing.Examples[name] = model.OpenAPIExample{Summary: r.Name, Value: media.Example}
+}
+
+// postmanSecurityScheme maps an auth to a named security scheme; noauth and
+// unsupported types yield none
+func postmanSecurityScheme(auth *model.PostmanAuth) (string, *model.OpenAPISecurityScheme) {
+	rows := postmanAuth(auth)
+	if len(rows) == 0 {
+		return "", nil
+	}
+	param := func(key string) string {
+		for _, p := range auth.OAuth2 {
+			if p.Key == key {
+				return scalarStringOf(p.Value)
+			}
+		}
+		return ""
+	}
+	switch auth.Type {
+	case "bearer":
+		return "bearerAuth", &model.OpenAPISecurityScheme{Type: "http", Scheme: "bearer"}
+	case "basic":
+		return "basicAuth", &model.OpenAPISecurityScheme{Type: "http", Scheme: "basic"}
+	case "apikey":
+		return "apiKeyAuth", &model.OpenAPISecurityScheme{Type: "apiKey", Name: rows[0].Key, In: rows[0].In}
+	case "oauth2":
+		scopes := map[string]string{}
+		for _, scope := range rows[0].Scopes {
+			scopes[scope] = makeReadable(scope)
+		}
+		flow := &model.OpenAPIOAuthFlow{
+			AuthorizationURL: param("authUrl"),
+			TokenURL:         param("accessTokenUrl"),
+			Scopes:           scopes,
+		}
+		flows := &model.OpenAPIOAuthFlows{}
+		switch param("grant_type") {
+		case "client_credentials":
+			flows.ClientCredentials = flow
+		case "password_credentials":
+			flows.Password = flow
+		case "implicit":
+			flows.Implicit = flow
+		default:
+			flows.AuthorizationCode = flow
+		}
+		return "oauth2", &model.OpenAPISecurityScheme{Type: "oauth2", Flows: flows}
+	default:
+		return "", nil
+	}
+}
+
+func postmanScopes(auth *model.PostmanAuth) []string {
+	scopes := []string{}
+	if rows := postmanAuth(auth); len(rows) > 0 && rows[0].Scopes != nil {
+		scopes = rows[0].Scopes
+	}
+	return scopes
+}
+
+func uniqueOperationID(used map[string]bool, name string) string {
+	var sb strings.Builder
+	for i, word := range strings.Fields(operationIDInvalid.ReplaceAllString(name, " ")) {
+		if i == 0 {
+			sb.WriteString(strings.ToLower(word[:1]) + word[1:])
+		} else {
+			sb.WriteString(strings.ToUpper(word[:1]) + word[1:])
+		}
+	}
+	base := firstNonEmpty(sb.String(), "operation")
+	id := base
+	for i := 2; used[id]; i++ {
+		id = fmt.Sprintf("%s%d", base, i)
+	}
+	used[id] = true
+	return id
+}
+
+func setOperation(item *model.OpenAPIPathItem, method string, op *model.OpenAPIOperation) {
+	switch strings.ToUpper(method) {
+	case http.MethodGet:
+		item.Get = op
+	case http.MethodPut:
+		item.Put = op
+	case http.MethodPost:
+		item.Post = op
+	case http.MethodDelete:
+		item.Delete = op
+	case http.MethodOptions:
+		item.Options = op
+	case http.MethodHead:
+		item.Head = op
+	case http.MethodPatch:
+		item.Patch = op
+	case http.MethodTrace:
+		item.Trace = op
+	}
+}
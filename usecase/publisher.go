@@ -0,0 +1,192 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/arifth/botthie/model"
+)
+
+// Publisher delivers the documents of a generation job to one target
+type Publisher interface {
+	// Name describes the target to users in the publish summary
+	Name() string
+	Publish(name string, docs []model.APIDocument) model.PublishResult
+}
+
+// Publish fans the documents out to every publisher at once and returns
+// their results in publisher order
+func Publish(name string, docs []model.APIDocument, publishers []Publisher) []model.PublishResult {
+	results := make([]model.PublishResult, len(publishers))
+	var wg sync.WaitGroup
+	for i, p := range publishers {
+		wg.Add(1)
+		go func(i int, p Publisher) {
+			defer wg.Done()
+			results[i] = p.Publish(name, docs)
+			results[i].Target = p.Name()
+		}(i, p)
+	}
+	wg.Wait()
+	return results
+}
+
+// PublishSummary formats the per-target results of a job as a chat message
+func PublishSummary(name string, results []model.PublishResult) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("📤 %s published to %d target(s)\n", name, len(results)))
+	for _, r := range results {
+		var partial *PartialError
+		switch {
+		case errors.As(r.Err, &partial) && partial.Published > 0:
+			sb.WriteString(fmt.Sprintf("⚠️ %s: %d page(s) published, %d failed\n", r.Target, partial.Published, len(partial.Failed)))
+			writeFailures(&sb, partial.Failed)
+		case errors.As(r.Err, &partial):
+			sb.WriteString(fmt.Sprintf("❌ %s: %d failed\n", r.Target, len(partial.Failed)))
+			writeFailures(&sb, partial.Failed)
+		case r.Err != nil:
+			sb.WriteString(fmt.Sprintf("❌ %s: %v\n", r.Target, r.Err))
+		case r.Location != "":
+			sb.WriteString(fmt.Sprintf("✅ %s: %d page(s) in %s\n", r.Target, r.Pages, r.Location))
+		default:
+			sb.WriteString(fmt.Sprintf("✅ %s: %d page(s)\n", r.Target, r.Pages))
+		}
+		for _, link := range r.Links {
+			if link.URL != "" {
+				sb.WriteString(fmt.Sprintf("   🔗 %s: %s\n", link.Title, link.URL))
+			}
+		}
+	}
+	return strings.TrimSuffix(sb.String(), "\n")
+}
+
+// writeFailures lists the failed items of a target, one per line
+func writeFailures(sb *strings.Builder, failed []error) {
+	for _, err := range failed {
+		sb.WriteString(fmt.Sprintf("   ❌ %v\n", err))
+	}
+}
+
+// ConfiguredPublishers returns the extra targets listed in PUBLISH_TARGETS,
+// e.g. "confluence,dir,git", that every job publishes to besides the output
+// chosen in the chat
+func ConfiguredPublishers(templ model.Templates, uc *Usecase) []Publisher {
+	var publishers []Publisher
+	for _, target := range strings.Split(os.Getenv("PUBLISH_TARGETS"), ",") {
+		switch strings.ToLower(strings.TrimSpace(target)) {
+		case "":
+		case "confluence":
+			publishers = append(publishers, ConfluencePublisher{Templates: templ, UC: uc})
+		case "dir":
+			publishers = append(publishers, DirectoryPublisher{Dir: os.Getenv("PUBLISH_DIR"), UC: uc})
+		case "git":
+			publishers = append(publishers, NewGitPublisher(uc))
+		default:
+			fmt.Printf("Unknown publish target %s\n", target)
+		}
+	}
+	return publishers
+}
+
+// writeMu serializes the publishers writing files, since publishers run
+// concurrently and the Directory and Git targets may share a directory
+var writeMu sync.Mutex
+
+// ListResult reduces the outcome of a Confluence publication to the number of
+// published pages and its error, a *PartialError when only some items failed
+func ListResult(list ListSuccess, err error) (int, error) {
+	return len(list.success), err
+}
+
+// DocumentError ties a failure of a target to the document it failed for,
+// Index being the position of the document in the job
+type DocumentError struct {
+	Doc   string
+	Index int
+	Err   error
+}
+
+func (e *DocumentError) Error() string {
+	return fmt.Sprintf("%s: %v", e.Doc, e.Err)
+}
+
+func (e *DocumentError) Unwrap() error {
+	return e.Err
+}
+
+// ConfluencePublisher publishes every document as a page tree in Confluence
+type ConfluencePublisher struct {
+	Templates model.Templates
+	UC        *Usecase
+}
+
+func (ConfluencePublisher) Name() string { return "Confluence" }
+
+func (p ConfluencePublisher) Publish(name string, docs []model.APIDocument) model.PublishResult {
+	var res model.PublishResult
+	var failed []error
+	for i, doc := range docs {
+		list, err := p.UC.PostDocumentToConfluence(doc, p.Templates, p.UC)
+		res.Links = append(res.Links, list.Links()...)
+		pages, err := ListResult(list, err)
+		res.Pages += pages
+		var partial *PartialError
+		switch {
+		case errors.As(err, &partial):
+			for _, f := range partial.Failed {
+				failed = append(failed, &DocumentError{Doc: doc.Name, Index: i, Err: f})
+			}
+		case err != nil:
+			// the parent page failed, so nothing of the document was published
+			failed = append(failed, &DocumentError{Doc: doc.Name, Index: i, Err: err})
+		}
+	}
+	if len(failed) > 0 {
+		res.Err = &PartialError{Published: res.Pages, Failed: failed}
+	}
+	return res
+}
+
+// DirectoryPublisher writes the documents as Markdown below Dir, replacing
+// the previous output of the same job
+type DirectoryPublisher struct {
+	Dir string
+	UC  *Usecase
+}
+
+func (DirectoryPublisher) Name() string { return "Directory" }
+
+func (p DirectoryPublisher) Publish(name string, docs []model.APIDocument) model.PublishResult {
+	if p.Dir == "" {
+		return model.PublishResult{Err: errors.New("PUBLISH_DIR is not set")}
+	}
+	root := filepath.Join(p.Dir, slugify(name))
+	files := p.UC.MarkdownFiles(docs, p.UC)
+	writeMu.Lock()
+	defer writeMu.Unlock()
+	if err := writeFiles(root, files); err != nil {
+		return model.PublishResult{Err: err}
+	}
+	return model.PublishResult{Pages: len(files), Location: root}
+}
+
+// ReplyPublisher sends the documents back to the chat with Send, which
+// renders them in the chat's output format
+type ReplyPublisher struct {
+	Format string
+	Send   func(name string, docs []model.APIDocument) error
+}
+
+func (p ReplyPublisher) Name() string { return "WhatsApp " + p.Format }
+
+func (p ReplyPublisher) Publish(name string, docs []model.APIDocument) model.PublishResult {
+	pages := 0
+	for _, doc := range docs {
+		pages += PageCount(doc)
+	}
+	return model.PublishResult{Pages: pages, Err: p.Send(name, docs)}
+}
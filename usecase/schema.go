@@ -0,0 +1,237 @@
+package usecase
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/arifth/botthie/model"
+)
+
+// maxSchemaDepth stops the flattening of deeply nested or recursive schemas
+const maxSchemaDepth = 8
+
+// schemaResolver follows local JSON references ("#/components/...") inside a
+// decoded YAML or JSON document
+type schemaResolver struct {
+	root map[string]interface{}
+}
+
+// deref returns node as an object, following $ref chains
+func (r schemaResolver) deref(node interface{}) map[string]interface{} {
+	obj, _ := node.(map[string]interface{})
+	for i := 0; obj != nil && i < maxSchemaDepth; i++ {
+		ref, ok := obj["$ref"].(string)
+		if !ok {
+			return obj
+		}
+		target, _ := r.lookup(ref).(map[string]interface{})
+		if target == nil {
+			return obj
+		}
+		obj = target
+	}
+	return obj
+}
+
+func (r schemaResolver) lookup(ref string) interface{} {
+	if !strings.HasPrefix(ref, "#/") {
+		return nil
+	}
+	var node interface{} = r.root
+	for _, part := range strings.Split(strings.TrimPrefix(ref, "#/"), "/") {
+		part = strings.ReplaceAll(strings.ReplaceAll(part, "~1", "/"), "~0", "~")
+		obj, ok := node.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		node = obj[part]
+	}
+	return node
+}
+
+// schemaFields flattens a JSON schema into numbered body field rows, using
+// dotted paths for nested objects and [] for array items
+func (r schemaResolver) schemaFields(schema interface{}) []model.BodyField {
+	var fields []model.BodyField
+	obj := r.deref(schema)
+	if obj == nil {
+		return nil
+	}
+	if len(r.properties(obj)) == 0 {
+		if t := schemaType(r, obj); t != "" {
+			fields = append(fields, model.BodyField{
+				Field:       "(body)",
+				Type:        t,
+				Mandatory:   "Yes",
+				Description: schemaDescription(obj),
+			})
+		}
+	} else {
+		r.flatten(obj, "", &fields, 0)
+	}
+	for i := range fields {
+		fields[i].Number = i + 1
+	}
+	return fields
+}
+
+func (r schemaResolver) flatten(obj map[string]interface{}, prefix string, fields *[]model.BodyField, depth int) {
+	if depth > maxSchemaDepth {
+		return
+	}
+	required := map[string]bool{}
+	for _, name := range r.required(obj) {
+		required[name] = true
+	}
+
+	props := r.properties(obj)
+	var names []string
+	for name := range props {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		prop := r.deref(props[name])
+		if prop == nil {
+			continue
+		}
+		path := prefix + name
+		mandatory := "No"
+		if required[name] {
+			mandatory = "Yes"
+		}
+		*fields = append(*fields, model.BodyField{
+			Field:       path,
+			Type:        schemaType(r, prop),
+			Mandatory:   mandatory,
+			Description: schemaDescription(prop),
+		})
+
+		if len(r.properties(prop)) > 0 {
+			r.flatten(prop, path+".", fields, depth+1)
+		}
+		if items := r.deref(prop["items"]); items != nil && len(r.properties(items)) > 0 {
+			r.flatten(items, path+"[].", fields, depth+1)
+		}
+	}
+}
+
+// properties merges the properties of a schema with those of its allOf,
+// oneOf and anyOf members
+func (r schemaResolver) properties(obj map[string]interface{}) map[string]interface{} {
+	props := map[string]interface{}{}
+	if own, ok := obj["properties"].(map[string]interface{}); ok {
+		for k, v := range own {
+			props[k] = v
+		}
+	}
+	for _, key := range []string{"allOf", "oneOf", "anyOf"} {
+		members, _ := obj[key].([]interface{})
+		for _, member := range members {
+			if m := r.deref(member); m != nil {
+				for k, v := range r.properties(m) {
+					if _, exists := props[k]; !exists {
+						props[k] = v
+					}
+				}
+			}
+		}
+	}
+	return props
+}
+
+func (r schemaResolver) required(obj map[string]interface{}) []string {
+	var names []string
+	list, _ := obj["required"].([]interface{})
+	for _, name := range list {
+		if s, ok := name.(string); ok {
+			names = append(names, s)
+		}
+	}
+	members, _ := obj["allOf"].([]interface{})
+	for _, member := range members {
+		if m := r.deref(member); m != nil {
+			names = append(names, r.required(m)...)
+		}
+	}
+	return names
+}
+
+func schemaType(r schemaResolver, obj map[string]interface{}) string {
+	t := ""
+	switch v := obj["type"].(type) {
+	case string:
+		t = v
+	case []interface{}:
+		var parts []string
+		for _, p := range v {
+			parts = append(parts, fmt.Sprint(p))
+		}
+		t = strings.Join(parts, "|")
+	}
+	if t == "" && len(r.properties(obj)) > 0 {
+		t = "object"
+	}
+	if format, ok := obj["format"].(string); ok && format != "" {
+		t = fmt.Sprintf("%s (%s)", t, format)
+	}
+	if t == "array" {
+		if items := r.deref(obj["items"]); items != nil {
+			t = fmt.Sprintf("array<%s>", schemaType(r, items))
+		}
+	}
+	return t
+}
+
+func schemaDescription(obj map[string]interface{}) string {
+	var parts []string
+	if d, ok := obj["description"].(string); ok && d != "" {
+		parts = append(parts, d)
+	} else if d, ok := obj["title"].(string); ok && d != "" {
+		parts = append(parts, d)
+	}
+	if values, ok := obj["enum"].([]interface{}); ok && len(values) > 0 {
+		var enum []string
+		for _, v := range values {
+			enum = append(enum, fmt.Sprint(v))
+		}
+		parts = append(parts, fmt.Sprintf("(one of: %s)", strings.Join(enum, ", ")))
+	}
+	if example, ok := obj["example"]; ok {
+		parts = append(parts, fmt.Sprintf("(example: %s)", scalarString(example)))
+	}
+	return strings.Join(parts, " ")
+}
+
+// scalarString formats a decoded value, falling back to JSON for collections
+func scalarString(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case map[string]interface{}, []interface{}:
+		data, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Sprint(v)
+		}
+		return string(data)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// bodyFields converts rows of a field table back to the format-neutral model
+func bodyFields(rows []model.BodyField) []model.APIField {
+	var fields []model.APIField
+	for _, row := range rows {
+		fields = append(fields, model.APIField{
+			Name:        row.Field,
+			Type:        row.Type,
+			Required:    row.Mandatory == "Yes",
+			Description: row.Description,
+		})
+	}
+	return fields
+}
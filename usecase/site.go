@@ -0,0 +1,156 @@
+package usecase
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/arifth/botthie/model"
+)
+
+// RenderSite renders a document as a self-contained static HTML site: an
+// index.html overview, one page per endpoint in a folder per service and one
+// page per shared type, all sharing a navigation with client-side search
+func (Usecase) RenderSite(doc model.APIDocument, templ model.Templates, uc *Usecase) map[string][]byte {
+	files := map[string][]byte{}
+	paths := pathAllocator{}
+
+	type page struct {
+		href    string
+		title   string
+		content string
+	}
+	var pages []page
+	var nav []model.SiteNavGroup
+	for _, service := range doc.Services {
+		dir := slugify(firstNonEmpty(service.Name, "endpoints"))
+		group := model.SiteNavGroup{Name: service.Name, Description: service.Description}
+		for _, endpoint := range service.Endpoints {
+			href := paths.next(dir, endpoint.Name, ".html")
+			group.Links = append(group.Links, model.SiteLink{
+				Title:   endpoint.Name,
+				Method:  endpoint.Method,
+				Href:    href,
+				Search:  strings.Join([]string{service.Name, endpoint.URL, endpoint.Kind, endpoint.Description}, " "),
+				Summary: summarize(endpoint.Description),
+			})
+			pages = append(pages, page{href, endpoint.Name, uc.ConvertToHTML(doc, templ.APIBook, service, endpoint)})
+		}
+		nav = append(nav, group)
+	}
+	if len(doc.Types) > 0 {
+		group := model.SiteNavGroup{Name: "Types"}
+		for _, t := range doc.Types {
+			href := paths.next("types", t.Name, ".html")
+			group.Links = append(group.Links, model.SiteLink{
+				Title:   t.Name,
+				Href:    href,
+				Search:  t.Kind + " " + t.Description,
+				Summary: summarize(t.Kind + " " + t.Description),
+			})
+			pages = append(pages, page{href, t.Name, uc.ConvertTypeToHTML(doc, templ.Type, t)})
+		}
+		nav = append(nav, group)
+	}
+
+	var facts []string
+	if doc.Format != "" {
+		facts = append(facts, "Format: "+doc.Format)
+	}
+	if doc.Version != "" {
+		facts = append(facts, "Version: "+doc.Version)
+	}
+	files["index.html"] = []byte(executeTemplate("site", templ.Site, model.SitePageData{
+		SiteName:    doc.Name,
+		Title:       "Overview",
+		Description: doc.Description,
+		Facts:       strings.Join(facts, " · "),
+		Current:     "index.html",
+		Nav:         nav,
+	}))
+	for _, p := range pages {
+		files[p.href] = []byte(executeTemplate("site", templ.Site, model.SitePageData{
+			SiteName: doc.Name,
+			Title:    p.title,
+			Root:     strings.Repeat("../", strings.Count(p.href, "/")),
+			Current:  p.href,
+			Content:  p.content,
+			Nav:      nav,
+		}))
+	}
+	return files
+}
+
+// DeliverSite renders the documents as one static site. When SITE_DIR is set
+// the site is written below it, otherwise it is sent back to the chat as a
+// zip. Several documents each get a folder linked from a root index
+func (Usecase) DeliverSite(name string, docs []model.APIDocument, templ model.Templates, uc *Usecase) error {
+	var files map[string][]byte
+	if len(docs) == 1 {
+		files = uc.RenderSite(docs[0], templ, uc)
+	} else {
+		files = map[string][]byte{}
+		folders := pathAllocator{}
+		group := model.SiteNavGroup{}
+		for _, doc := range docs {
+			prefix := folders.unique(slugify(doc.Name), "") + "/"
+			for path, content := range uc.RenderSite(doc, templ, uc) {
+				files[prefix+path] = content
+			}
+			group.Links = append(group.Links, model.SiteLink{
+				Title:   doc.Name,
+				Href:    prefix + "index.html",
+				Search:  doc.Format + " " + doc.Description,
+				Summary: summarize(doc.Description),
+			})
+		}
+		files["index.html"] = []byte(executeTemplate("site", templ.Site, model.SitePageData{
+			SiteName: name,
+			Title:    "Overview",
+			Current:  "index.html",
+			Nav:      []model.SiteNavGroup{group},
+		}))
+	}
+
+	if dir := os.Getenv("SITE_DIR"); dir != "" {
+		root := filepath.Join(dir, slugify(name))
+		if err := writeFiles(root, files); err != nil {
+			return err
+		}
+		uc.SendMessageAll(uc, fmt.Sprintf("🌐 Static site of %s written to %s", name, root))
+		return nil
+	}
+	caption := fmt.Sprintf("🌐 Static HTML site of %s, open index.html", name)
+	return uc.SendZip(slugify(name)+"-site.zip", files, caption, uc)
+}
+
+// writeFiles replaces the content of root with the files keyed by their
+// slash separated path
+func writeFiles(root string, files map[string][]byte) error {
+	if err := os.RemoveAll(root); err != nil {
+		return err
+	}
+	for path, content := range files {
+		target := filepath.Join(root, filepath.FromSlash(path))
+		if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(target, content, 0o644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// summarize shortens a description to its first line for overviews
+func summarize(s string) string {
+	s = strings.TrimSpace(s)
+	if idx := strings.IndexAny(s, "\r\n"); idx >= 0 {
+		s = s[:idx]
+	}
+	if runes := []rune(s); len(runes) > 120 {
+		s = string(runes[:117]) + "..."
+	}
+	return s
+}
@@ -0,0 +1,61 @@
+package usecase
+
+import (
+	"strings"
+
+	"github.com/arifth/botthie/model"
+)
+
+// templateFuncs are available to every page template. The Confluence template
+// uses them to fill in its macros, the HTML template to link only web URLs
+var templateFuncs = map[string]interface{}{
+	"cdata":       storageCDATA,
+	"codeLang":    storageLanguage,
+	"collapse":    storageCollapse,
+	"statusColor": storageStatusColor,
+	"httpURL":     httpURL,
+}
+
+// ConvertToStorage renders the page of one endpoint in Confluence storage
+// format, using the code, status, expand and panel macros of the Confluence
+// template. The properties fill its page properties macro
+func (Usecase) ConvertToStorage(doc model.APIDocument, dataTempl string, service model.APIService, endpoint model.APIEndpoint, properties []model.APIAttribute) string {
+	data := model.TemplateData{
+		CollectionName: doc.Name,
+		Requests:       requestData(service, endpoint),
+		Properties:     properties,
+	}
+	return executeTemplate("confluence", dataTempl, data)
+}
+
+// storageCDATA wraps text in a CDATA section, splitting the sequences that
+// would end it early
+func storageCDATA(text string) string {
+	return "<![CDATA[" + strings.ReplaceAll(text, "]]>", "]]]]><![CDATA[>") + "]]>"
+}
+
+// storageLanguage picks the language of the code macro from the body
+func storageLanguage(body string) string {
+	return firstNonEmpty(bodyLanguage(body), "text")
+}
+
+// storageCollapse collapses the code macro of bodies longer than a screen
+func storageCollapse(body string) bool {
+	return strings.Count(strings.TrimSpace(body), "\n") >= 20
+}
+
+// storageStatusColor returns the colour of the status lozenge of a method
+func storageStatusColor(method string) string {
+	switch strings.ToUpper(method) {
+	case "GET":
+		return "Blue"
+	case "POST":
+		return "Green"
+	case "PUT", "PATCH":
+		return "Yellow"
+	case "DELETE":
+		return "Red"
+	default:
+		return "Grey"
+	}
+}
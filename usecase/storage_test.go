@@ -0,0 +1,67 @@
+package usecase
+
+import (
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/arifth/botthie/model"
+)
+
+func TestConvertToStorage(t *testing.T) {
+	templ, err := os.ReadFile("../template/confluence.html")
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc, service, endpoint := editsEndpoint(`Creates a <script>alert(1)</script> pet`)
+	page := Usecase{}.ConvertToStorage(doc, string(templ), service, endpoint, nil)
+	for _, want := range []string{
+		`<ac:parameter ac:name="colour">Green</ac:parameter>`,
+		`<ac:parameter ac:name="language">json</ac:parameter>`,
+		`<![CDATA[{"name":"Rex","tag":"dog"}]]>`,
+		`Creates a &lt;script&gt;alert(1)&lt;/script&gt; pet`,
+		`<h1>Request Body</h1>`,
+	} {
+		if !strings.Contains(page, want) {
+			t.Errorf("page lacks %s:\n%s", want, page)
+		}
+	}
+	if strings.Contains(page, "<script>") {
+		t.Errorf("page holds the unescaped description")
+	}
+}
+
+func TestConvertToADF(t *testing.T) {
+	doc, service, endpoint := editsEndpoint("Creates a pet")
+	endpoint.Examples = []model.APIExample{{Name: "templated", Request: `{"name": {{petName}}}`}}
+	page := Usecase{}.ConvertToADF(doc, service, endpoint)
+	if page.Type != "doc" || page.Version != 1 {
+		t.Fatalf("page is not an ADF document: %+v", page)
+	}
+	if _, err := json.Marshal(page); err != nil {
+		t.Fatal(err)
+	}
+
+	languages := map[string]string{}
+	var walk func(n model.ADFNode)
+	walk = func(n model.ADFNode) {
+		if n.Type == "codeBlock" && len(n.Content) > 0 {
+			language, _ := n.Attrs["language"].(string)
+			languages[n.Content[0].Text] = language
+		}
+		for _, c := range n.Content {
+			walk(c)
+		}
+	}
+	walk(page)
+	for code, want := range map[string]string{
+		`{"name":"Rex","tag":"dog"}`: "json",
+		// a templated body is not valid JSON, so it is not labelled as such
+		`{"name": {{petName}}}`: "",
+	} {
+		if got, ok := languages[code]; !ok || got != want {
+			t.Errorf("code block %s language = %q (found %v), want %q", code, got, ok, want)
+		}
+	}
+}
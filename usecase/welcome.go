@@ -8,5 +8,6 @@ func FirstAttempt() {
 	fmt.Println("   1. Open WhatsApp on your phone")
 	fmt.Println("   2. Go to Settings > Linked Devices")
 	fmt.Println("   3. Tap 'Link a Device'")
-	fmt.Println("   4. Scan the QR code below\n")
+	fmt.Println("   4. Scan the QR code below")
+	fmt.Println()
 }
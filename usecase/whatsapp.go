@@ -5,8 +5,11 @@ import (
 	"fmt"
 	"net/http"
 	"os"
+	"path"
 	"path/filepath"
+	"strings"
 
+	"github.com/arifth/botthie/util"
 	"go.mau.fi/whatsmeow"
 	"go.mau.fi/whatsmeow/proto/waE2E"
 	"go.mau.fi/whatsmeow/types"
@@ -19,8 +22,7 @@ func SendDocumentAndImage(client *whatsmeow.Client, jid types.JID, filePath stri
 		return err
 	}
 
-	dataType := http.DetectContentType(data)
-	fmt.Println(dataType)
+	dataType := fileMimetype(filePath, data)
 
 	switch dataType {
 	case "image/png":
@@ -31,7 +33,7 @@ func SendDocumentAndImage(client *whatsmeow.Client, jid types.JID, filePath stri
 		bodyMsg := &waE2E.Message{
 			ImageMessage: &waE2E.ImageMessage{
 				Caption:       proto.String(text),
-				Mimetype:      proto.String(http.DetectContentType(data)),
+				Mimetype:      proto.String(dataType),
 				URL:           &resp.URL,
 				DirectPath:    &resp.DirectPath,
 				MediaKey:      resp.MediaKey,
@@ -53,7 +55,7 @@ func SendDocumentAndImage(client *whatsmeow.Client, jid types.JID, filePath stri
 		bodyMsg := &waE2E.Message{
 			DocumentMessage: &waE2E.DocumentMessage{
 				FileName:      proto.String(filepath.Base(filePath)),
-				Mimetype:      proto.String(http.DetectContentType(data)),
+				Mimetype:      proto.String(dataType),
 				URL:           &resp.URL,
 				DirectPath:    &resp.DirectPath,
 				MediaKey:      resp.MediaKey,
@@ -71,6 +73,31 @@ func SendDocumentAndImage(client *whatsmeow.Client, jid types.JID, filePath stri
 	return nil
 }
 
+// fileMimetypes are the types of the files the bot sends, by extension.
+// Content sniffing cannot tell them apart: docx and xlsx files are zips and
+// Markdown is plain text
+var fileMimetypes = map[string]string{
+	".docx": "application/vnd.openxmlformats-officedocument.wordprocessingml.document",
+	".xlsx": "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+	".pdf":  "application/pdf",
+	".zip":  "application/zip",
+	".md":   "text/markdown",
+	".html": "text/html",
+	".json": "application/json",
+	".yaml": "application/yaml",
+	".yml":  "application/yaml",
+	".png":  "image/png",
+}
+
+// fileMimetype returns the type of a file from its extension, sniffing its
+// content for other extensions
+func fileMimetype(filePath string, data []byte) string {
+	if mimetype, ok := fileMimetypes[strings.ToLower(filepath.Ext(filePath))]; ok {
+		return mimetype
+	}
+	return http.DetectContentType(data)
+}
+
 func (Usecase) SendMessageAll(uc *Usecase, text string) {
 	msg := &waE2E.Message{
 		Conversation: proto.String(text),
@@ -81,3 +108,32 @@ func (Usecase) SendMessageAll(uc *Usecase, text string) {
 		fmt.Printf("Error sending message: %v\n", err)
 	}
 }
+
+// SendZip zips the files and sends the archive as a document named fileName
+func (Usecase) SendZip(fileName string, files map[string][]byte, caption string, uc *Usecase) error {
+	data, err := util.WriteZip(files)
+	if err != nil {
+		return err
+	}
+	return uc.SendFile(fileName, data, caption, uc)
+}
+
+// SendFile sends data as a document named fileName. Only the last element of
+// fileName is used, since it may come from the uploaded file
+func (Usecase) SendFile(fileName string, data []byte, caption string, uc *Usecase) error {
+	name := path.Base(path.Clean("/" + strings.ReplaceAll(fileName, "\\", "/")))
+	if name == "/" {
+		return fmt.Errorf("invalid file name %q", fileName)
+	}
+	// SendDocumentAndImage names the document after the file it reads
+	dir, err := os.MkdirTemp("", "botthie")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+	filePath := filepath.Join(dir, name)
+	if err := os.WriteFile(filePath, data, 0o644); err != nil {
+		return err
+	}
+	return SendDocumentAndImage(uc.client, uc.jdID, filePath, caption)
+}
@@ -0,0 +1,23 @@
+package usecase
+
+import "testing"
+
+func TestFileMimetype(t *testing.T) {
+	tests := []struct {
+		file string
+		data string
+		want string
+	}{
+		{"API.docx", "PK\x03\x04", "application/vnd.openxmlformats-officedocument.wordprocessingml.document"},
+		{"API-dictionary.XLSX", "PK\x03\x04", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"},
+		{"README.md", "# API", "text/markdown"},
+		{"API-md.zip", "PK\x03\x04", "application/zip"},
+		{"openapi.yaml", "openapi: 3.1.0", "application/yaml"},
+		{"diagram", "\x89PNG\r\n\x1a\n", "image/png"},
+	}
+	for _, tt := range tests {
+		if got := fileMimetype(tt.file, []byte(tt.data)); got != tt.want {
+			t.Errorf("fileMimetype(%q) = %q, want %q", tt.file, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,732 @@
+package usecase
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/arifth/botthie/model"
+)
+
+const (
+	nsWSDL     = "http://schemas.xmlsoap.org/wsdl/"
+	nsSOAP11   = "http://schemas.xmlsoap.org/wsdl/soap/"
+	nsSOAP12   = "http://schemas.xmlsoap.org/wsdl/soap12/"
+	nsXSD      = "http://www.w3.org/2001/XMLSchema"
+	nsEnvelope = "http://schemas.xmlsoap.org/soap/envelope/"
+	nsEnv12    = "http://www.w3.org/2003/05/soap-envelope"
+)
+
+// xmlNode is a namespace-aware XML element that remembers the prefixes in
+// scope, which WSDL and XSD need to resolve QName attribute values
+type xmlNode struct {
+	Space    string
+	Local    string
+	Attrs    map[string]string
+	NS       map[string]string
+	Children []*xmlNode
+	Text     string
+}
+
+type qname struct {
+	ns    string
+	local string
+}
+
+// xsdDecl is a global element or type declaration with its schema context
+type xsdDecl struct {
+	node      *xmlNode
+	ns        string
+	qualified bool
+}
+
+type xsdIndex struct {
+	elements map[qname]xsdDecl
+	types    map[qname]xsdDecl
+}
+
+func parseXMLTree(data []byte) (*xmlNode, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	var stack []*xmlNode
+	var root *xmlNode
+	for {
+		tok, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			node := &xmlNode{
+				Space: t.Name.Space,
+				Local: t.Name.Local,
+				Attrs: map[string]string{},
+				NS:    map[string]string{},
+			}
+			if len(stack) > 0 {
+				for k, v := range stack[len(stack)-1].NS {
+					node.NS[k] = v
+				}
+			}
+			for _, attr := range t.Attr {
+				switch {
+				case attr.Name.Space == "xmlns":
+					node.NS[attr.Name.Local] = attr.Value
+				case attr.Name.Space == "" && attr.Name.Local == "xmlns":
+					node.NS[""] = attr.Value
+				default:
+					node.Attrs[attr.Name.Local] = attr.Value
+				}
+			}
+			if len(stack) > 0 {
+				parent := stack[len(stack)-1]
+				parent.Children = append(parent.Children, node)
+			} else {
+				root = node
+			}
+			stack = append(stack, node)
+		case xml.EndElement:
+			stack = stack[:len(stack)-1]
+		case xml.CharData:
+			if len(stack) > 0 {
+				stack[len(stack)-1].Text += string(t)
+			}
+		}
+	}
+	if root == nil {
+		return nil, fmt.Errorf("empty XML document")
+	}
+	return root, nil
+}
+
+func (n *xmlNode) children(space string, local string) []*xmlNode {
+	var res []*xmlNode
+	for _, c := range n.Children {
+		if c.Space == space && c.Local == local {
+			res = append(res, c)
+		}
+	}
+	return res
+}
+
+func (n *xmlNode) child(space string, local string) *xmlNode {
+	for _, c := range n.Children {
+		if c.Space == space && c.Local == local {
+			return c
+		}
+	}
+	return nil
+}
+
+// qname resolves a prefixed attribute value such as "tns:Order"
+func (n *xmlNode) qname(value string) qname {
+	if idx := strings.Index(value, ":"); idx >= 0 {
+		return qname{ns: n.NS[value[:idx]], local: value[idx+1:]}
+	}
+	return qname{ns: n.NS[""], local: value}
+}
+
+func (n *xmlNode) documentation() string {
+	if doc := n.child(nsWSDL, "documentation"); doc != nil {
+		return strings.TrimSpace(doc.Text)
+	}
+	if ann := n.child(nsXSD, "annotation"); ann != nil {
+		if doc := ann.child(nsXSD, "documentation"); doc != nil {
+			return strings.Join(strings.Fields(doc.Text), " ")
+		}
+	}
+	return ""
+}
+
+// IsWSDL reports whether data is a WSDL 1.1 definitions document
+func IsWSDL(data []byte) bool {
+	root, err := parseXMLTree(data)
+	return err == nil && root.Space == nsWSDL && root.Local == "definitions"
+}
+
+// ParseWSDL builds the documentation model of the main WSDL document,
+// resolving its wsdl:import and xsd:import targets among all files keyed by
+// their path
+func ParseWSDL(main string, files map[string][]byte) (model.WSDLDoc, error) {
+	var defs *xmlNode
+	index := xsdIndex{elements: map[qname]xsdDecl{}, types: map[qname]xsdDecl{}}
+
+	var paths []string
+	for p := range files {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+
+	var wsdlDefs []*xmlNode
+	for _, p := range paths {
+		ext := strings.ToLower(path.Ext(p))
+		if ext != ".wsdl" && ext != ".xsd" && ext != ".xml" {
+			continue
+		}
+		root, err := parseXMLTree(files[p])
+		if err != nil {
+			return model.WSDLDoc{}, fmt.Errorf("%s: %w", p, err)
+		}
+		switch {
+		case root.Space == nsWSDL && root.Local == "definitions":
+			wsdlDefs = append(wsdlDefs, root)
+			if p == main {
+				defs = root
+			}
+			if types := root.child(nsWSDL, "types"); types != nil {
+				for _, schema := range types.children(nsXSD, "schema") {
+					index.add(schema)
+				}
+			}
+		case root.Space == nsXSD && root.Local == "schema":
+			index.add(root)
+		}
+	}
+	if defs == nil {
+		return model.WSDLDoc{}, fmt.Errorf("%s is not a WSDL document", main)
+	}
+
+	doc := model.WSDLDoc{
+		Name:            defs.Attrs["name"],
+		TargetNamespace: defs.Attrs["targetNamespace"],
+	}
+	if doc.Name == "" {
+		doc.Name = "WSDL"
+	}
+
+	messages := map[qname]*xmlNode{}
+	portTypes := map[qname]*xmlNode{}
+	bindings := map[qname]*xmlNode{}
+	var bindingOrder []qname
+	for _, d := range wsdlDefs {
+		tns := d.Attrs["targetNamespace"]
+		for _, m := range d.children(nsWSDL, "message") {
+			messages[qname{tns, m.Attrs["name"]}] = m
+		}
+		for _, pt := range d.children(nsWSDL, "portType") {
+			portTypes[qname{tns, pt.Attrs["name"]}] = pt
+		}
+		for _, b := range d.children(nsWSDL, "binding") {
+			key := qname{tns, b.Attrs["name"]}
+			bindings[key] = b
+			bindingOrder = append(bindingOrder, key)
+		}
+	}
+
+	// the other definitions are wsdl:import targets contributing messages,
+	// port types and bindings; only the services of the main one are
+	// documented, falling back to its bindings when it declares none
+	w := wsdlBuilder{index: index, messages: messages, portTypes: portTypes}
+	documented := map[qname]bool{}
+	for _, svc := range defs.children(nsWSDL, "service") {
+		for _, port := range svc.children(nsWSDL, "port") {
+			key := port.qname(port.Attrs["binding"])
+			binding, ok := bindings[key]
+			if !ok {
+				continue
+			}
+			documented[key] = true
+			address := ""
+			for _, ns := range []string{nsSOAP11, nsSOAP12} {
+				if addr := port.child(ns, "address"); addr != nil {
+					address = addr.Attrs["location"]
+				}
+			}
+			doc.Operations = append(doc.Operations, w.bindingOperations(svc.Attrs["name"], port.Attrs["name"], address, binding)...)
+		}
+	}
+	tns := defs.Attrs["targetNamespace"]
+	for _, key := range bindingOrder {
+		if key.ns == tns && !documented[key] {
+			doc.Operations = append(doc.Operations, w.bindingOperations("", "", "", bindings[key])...)
+		}
+	}
+	if len(doc.Operations) == 0 {
+		return doc, fmt.Errorf("no SOAP operations found: %w", ErrNothingToDocument)
+	}
+	return doc, nil
+}
+
+func (x xsdIndex) add(schema *xmlNode) {
+	tns := schema.Attrs["targetNamespace"]
+	qualified := schema.Attrs["elementFormDefault"] == "qualified"
+	for _, c := range schema.Children {
+		if c.Space != nsXSD {
+			continue
+		}
+		decl := xsdDecl{node: c, ns: tns, qualified: qualified}
+		switch c.Local {
+		case "element":
+			x.elements[qname{tns, c.Attrs["name"]}] = decl
+		case "complexType", "simpleType":
+			x.types[qname{tns, c.Attrs["name"]}] = decl
+		}
+	}
+}
+
+type wsdlBuilder struct {
+	index     xsdIndex
+	messages  map[qname]*xmlNode
+	portTypes map[qname]*xmlNode
+}
+
+func (w wsdlBuilder) bindingOperations(service string, port string, address string, binding *xmlNode) []model.WSDLOperation {
+	soapNS, version := nsSOAP11, "1.1"
+	if binding.child(nsSOAP12, "binding") != nil {
+		soapNS, version = nsSOAP12, "1.2"
+	}
+	style := "document"
+	if sb := binding.child(soapNS, "binding"); sb != nil && sb.Attrs["style"] != "" {
+		style = sb.Attrs["style"]
+	}
+
+	portType := w.portTypes[binding.qname(binding.Attrs["type"])]
+	var ops []model.WSDLOperation
+	for _, bop := range binding.children(nsWSDL, "operation") {
+		op := model.WSDLOperation{
+			Service:     service,
+			Port:        port,
+			Binding:     binding.Attrs["name"],
+			Name:        bop.Attrs["name"],
+			Address:     address,
+			Style:       style,
+			SOAPVersion: version,
+		}
+		if so := bop.child(soapNS, "operation"); so != nil {
+			op.SOAPAction = so.Attrs["soapAction"]
+			if so.Attrs["style"] != "" {
+				op.Style = so.Attrs["style"]
+			}
+		}
+		bodyNS := func(dir string) string {
+			if ref := bop.child(nsWSDL, dir); ref != nil {
+				if body := ref.child(soapNS, "body"); body != nil {
+					return body.Attrs["namespace"]
+				}
+			}
+			return ""
+		}
+
+		var ptOp *xmlNode
+		if portType != nil {
+			for _, candidate := range portType.children(nsWSDL, "operation") {
+				if candidate.Attrs["name"] == op.Name {
+					ptOp = candidate
+					break
+				}
+			}
+		}
+		if ptOp != nil {
+			op.Documentation = firstNonEmpty(ptOp.documentation(), bop.documentation())
+			if in := ptOp.child(nsWSDL, "input"); in != nil {
+				op.InputElement, op.Input, op.SampleRequest = w.messageBody(in, op.Name, op.Style, bodyNS("input"), version)
+			}
+			if out := ptOp.child(nsWSDL, "output"); out != nil {
+				op.OutputElement, op.Output, op.SampleResponse = w.messageBody(out, op.Name+"Response", op.Style, bodyNS("output"), version)
+			}
+			for _, fault := range ptOp.children(nsWSDL, "fault") {
+				op.Faults = append(op.Faults, firstNonEmpty(fault.Attrs["name"], fault.qname(fault.Attrs["message"]).local))
+			}
+		}
+		ops = append(ops, op)
+	}
+	return ops
+}
+
+// messageBody returns the body element name, the field table and a sample
+// envelope for one input or output message
+func (w wsdlBuilder) messageBody(ref *xmlNode, wrapper string, style string, rpcNS string, version string) (string, []model.BodyField, string) {
+	msg := w.messages[ref.qname(ref.Attrs["message"])]
+	if msg == nil {
+		return "", nil, ""
+	}
+
+	var fields []model.BodyField
+	var body strings.Builder
+	elementName := ""
+	parts := msg.children(nsWSDL, "part")
+
+	if style == "rpc" {
+		elementName = wrapper
+		body.WriteString(fmt.Sprintf("      <op:%s xmlns:op=\"%s\">\n", wrapper, rpcNS))
+		for _, part := range parts {
+			name := part.Attrs["name"]
+			t := part.qname(part.Attrs["type"])
+			fields = append(fields, model.BodyField{Field: name, Type: t.local, Mandatory: "Yes"})
+			body.WriteString(fmt.Sprintf("         <%s>", name))
+			decl, ok := w.index.types[t]
+			if ok && decl.node.Local == "complexType" {
+				body.WriteString("\n")
+				w.index.contentFields(decl.node, decl, name+".", &fields, 0)
+				w.index.sampleContent(decl.node, decl, "            ", rpcNS, &body, 0)
+				body.WriteString("         ")
+			} else {
+				body.WriteString(w.index.sampleValue(t, decl))
+			}
+			body.WriteString(fmt.Sprintf("</%s>\n", name))
+		}
+		body.WriteString(fmt.Sprintf("      </op:%s>\n", wrapper))
+	} else {
+		for _, part := range parts {
+			el := part.qname(part.Attrs["element"])
+			decl, ok := w.index.elements[el]
+			if !ok {
+				continue
+			}
+			elementName = el.local
+			w.index.elementContentFields(decl, &fields)
+			w.index.sampleElement(decl.node, decl, "      ", "", &body, 0)
+		}
+	}
+	for i := range fields {
+		fields[i].Number = i + 1
+	}
+
+	envNS := nsEnvelope
+	if version == "1.2" {
+		envNS = nsEnv12
+	}
+	envelope := fmt.Sprintf("<soapenv:Envelope xmlns:soapenv=\"%s\">\n   <soapenv:Header/>\n   <soapenv:Body>\n%s   </soapenv:Body>\n</soapenv:Envelope>", envNS, body.String())
+	return elementName, fields, envelope
+}
+
+// elementContentFields lists the children of a global element, which is
+// what a document/literal body table documents
+func (x xsdIndex) elementContentFields(decl xsdDecl, fields *[]model.BodyField) {
+	if ct := x.complexTypeOf(decl.node, decl); ct != nil {
+		x.contentFields(ct.node, *ct, "", fields, 0)
+		return
+	}
+	t := decl.node.qname(decl.node.Attrs["type"])
+	*fields = append(*fields, model.BodyField{
+		Field:       decl.node.Attrs["name"],
+		Type:        t.local,
+		Mandatory:   "Yes",
+		Description: decl.node.documentation(),
+	})
+}
+
+// complexTypeOf returns the named or anonymous complex type of an element
+func (x xsdIndex) complexTypeOf(el *xmlNode, ctx xsdDecl) *xsdDecl {
+	if ct := el.child(nsXSD, "complexType"); ct != nil {
+		return &xsdDecl{node: ct, ns: ctx.ns, qualified: ctx.qualified}
+	}
+	if typeAttr, ok := el.Attrs["type"]; ok {
+		if decl, ok := x.types[el.qname(typeAttr)]; ok && decl.node.Local == "complexType" {
+			return &decl
+		}
+	}
+	return nil
+}
+
+func (x xsdIndex) contentFields(ct *xmlNode, ctx xsdDecl, prefix string, fields *[]model.BodyField, depth int) {
+	if depth > maxSchemaDepth {
+		return
+	}
+	for _, c := range ct.Children {
+		if c.Space != nsXSD {
+			continue
+		}
+		switch c.Local {
+		case "sequence", "all", "choice":
+			x.contentFields(c, ctx, prefix, fields, depth)
+		case "complexContent", "simpleContent":
+			for _, derivation := range c.Children {
+				if derivation.Space != nsXSD {
+					continue
+				}
+				if derivation.Local == "extension" {
+					if base, ok := x.types[derivation.qname(derivation.Attrs["base"])]; ok && base.node.Local == "complexType" {
+						x.contentFields(base.node, base, prefix, fields, depth+1)
+					}
+				}
+				x.contentFields(derivation, ctx, prefix, fields, depth)
+			}
+		case "element":
+			x.elementField(c, ctx, prefix, fields, depth)
+		case "attribute":
+			name := c.Attrs["name"]
+			if name == "" {
+				name = c.qname(c.Attrs["ref"]).local
+			}
+			mandatory := "No"
+			if c.Attrs["use"] == "required" {
+				mandatory = "Yes"
+			}
+			*fields = append(*fields, model.BodyField{
+				Field:       prefix + "@" + name,
+				Type:        c.qname(c.Attrs["type"]).local,
+				Mandatory:   mandatory,
+				Description: c.documentation(),
+			})
+		case "any":
+			*fields = append(*fields, model.BodyField{
+				Field:     prefix + "(any)",
+				Type:      "any",
+				Mandatory: occursMandatory(c),
+			})
+		}
+	}
+}
+
+func (x xsdIndex) elementField(el *xmlNode, ctx xsdDecl, prefix string, fields *[]model.BodyField, depth int) {
+	target, targetCtx := x.refElement(el, ctx)
+	name := target.Attrs["name"]
+	path := prefix + name
+
+	typeName := "complex"
+	description := target.documentation()
+	ct := x.complexTypeOf(target, targetCtx)
+	if t, ok := target.Attrs["type"]; ok {
+		q := target.qname(t)
+		typeName = q.local
+		if st, ok := x.types[q]; ok && st.node.Local == "simpleType" {
+			description = strings.TrimSpace(description + " " + enumerationText(st.node))
+		}
+	} else if st := target.child(nsXSD, "simpleType"); st != nil {
+		if r := st.child(nsXSD, "restriction"); r != nil {
+			typeName = r.qname(r.Attrs["base"]).local
+		}
+		description = strings.TrimSpace(description + " " + enumerationText(st))
+	}
+	if maxOccurs := el.Attrs["maxOccurs"]; maxOccurs != "" && maxOccurs != "0" && maxOccurs != "1" {
+		typeName += "[]"
+		if maxOccurs != "unbounded" {
+			description = strings.TrimSpace(fmt.Sprintf("%s (up to %s)", description, maxOccurs))
+		}
+	}
+
+	*fields = append(*fields, model.BodyField{
+		Field:       path,
+		Type:        typeName,
+		Mandatory:   occursMandatory(el),
+		Description: description,
+	})
+	if ct != nil {
+		x.contentFields(ct.node, *ct, path+".", fields, depth+1)
+	}
+}
+
+// refElement follows an element ref="..." to its global declaration
+func (x xsdIndex) refElement(el *xmlNode, ctx xsdDecl) (*xmlNode, xsdDecl) {
+	if ref, ok := el.Attrs["ref"]; ok {
+		if decl, ok := x.elements[el.qname(ref)]; ok {
+			return decl.node, decl
+		}
+	}
+	return el, ctx
+}
+
+func occursMandatory(el *xmlNode) string {
+	if el.Attrs["minOccurs"] == "0" || el.Attrs["nillable"] == "true" {
+		return "No"
+	}
+	return "Yes"
+}
+
+func enumerationText(simpleType *xmlNode) string {
+	r := simpleType.child(nsXSD, "restriction")
+	if r == nil {
+		return ""
+	}
+	var values []string
+	for _, e := range r.children(nsXSD, "enumeration") {
+		values = append(values, e.Attrs["value"])
+	}
+	if len(values) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("(one of: %s)", strings.Join(values, ", "))
+}
+
+// sampleElement writes el as a sample XML fragment. Global and qualified
+// elements use the ns prefix, which is redeclared whenever the namespace
+// differs from the one bound in the enclosing element (scopeNS)
+func (x xsdIndex) sampleElement(el *xmlNode, ctx xsdDecl, indent string, scopeNS string, sb *strings.Builder, depth int) {
+	if depth > maxSchemaDepth {
+		return
+	}
+	target, targetCtx := x.refElement(el, ctx)
+	name := target.Attrs["name"]
+	tag := name
+	open := name
+	if scopeNS == "" || targetCtx.qualified || target != el {
+		tag = "ns:" + name
+		open = tag
+		if targetCtx.ns != scopeNS {
+			open = fmt.Sprintf("%s xmlns:ns=\"%s\"", tag, targetCtx.ns)
+			scopeNS = targetCtx.ns
+		}
+	}
+
+	if ct := x.complexTypeOf(target, targetCtx); ct != nil {
+		sb.WriteString(fmt.Sprintf("%s<%s>\n", indent, open))
+		x.sampleContent(ct.node, *ct, indent+"   ", scopeNS, sb, depth+1)
+		sb.WriteString(fmt.Sprintf("%s</%s>\n", indent, tag))
+		return
+	}
+
+	var value string
+	if t, ok := target.Attrs["type"]; ok {
+		q := target.qname(t)
+		value = x.sampleValue(q, x.types[q])
+	} else if st := target.child(nsXSD, "simpleType"); st != nil {
+		value = x.sampleValue(qname{}, xsdDecl{node: st})
+	} else {
+		value = "?"
+	}
+	sb.WriteString(fmt.Sprintf("%s<%s>%s</%s>\n", indent, open, value, tag))
+}
+
+func (x xsdIndex) sampleContent(ct *xmlNode, ctx xsdDecl, indent string, scopeNS string, sb *strings.Builder, depth int) {
+	if depth > maxSchemaDepth {
+		return
+	}
+	for _, c := range ct.Children {
+		if c.Space != nsXSD {
+			continue
+		}
+		switch c.Local {
+		case "sequence", "all":
+			x.sampleContent(c, ctx, indent, scopeNS, sb, depth)
+		case "choice":
+			// a sample only needs one of the alternatives
+			for _, alt := range c.Children {
+				if alt.Space == nsXSD && alt.Local == "element" {
+					x.sampleElement(alt, ctx, indent, scopeNS, sb, depth)
+					break
+				}
+			}
+		case "complexContent":
+			for _, derivation := range c.Children {
+				if derivation.Space != nsXSD {
+					continue
+				}
+				if derivation.Local == "extension" {
+					if base, ok := x.types[derivation.qname(derivation.Attrs["base"])]; ok && base.node.Local == "complexType" {
+						x.sampleContent(base.node, base, indent, scopeNS, sb, depth+1)
+					}
+				}
+				x.sampleContent(derivation, ctx, indent, scopeNS, sb, depth)
+			}
+		case "element":
+			x.sampleElement(c, ctx, indent, scopeNS, sb, depth)
+		}
+	}
+}
+
+// sampleValue returns a placeholder for a simple type
+func (x xsdIndex) sampleValue(t qname, decl xsdDecl) string {
+	if decl.node != nil && decl.node.Local == "simpleType" {
+		if r := decl.node.child(nsXSD, "restriction"); r != nil {
+			if e := r.child(nsXSD, "enumeration"); e != nil {
+				return e.Attrs["value"]
+			}
+			t = r.qname(r.Attrs["base"])
+		}
+	}
+	switch t.local {
+	case "int", "integer", "long", "short", "byte", "nonNegativeInteger", "positiveInteger", "unsignedInt", "unsignedLong":
+		return "0"
+	case "decimal", "double", "float":
+		return "0.0"
+	case "boolean":
+		return "false"
+	case "date":
+		return "2006-01-02"
+	case "dateTime":
+		return "2006-01-02T15:04:05Z"
+	default:
+		return "?"
+	}
+}
+
+type wsdlImporter struct{}
+
+func (wsdlImporter) Name() string { return "WSDL document" }
+
+func (wsdlImporter) Detect(fileName string, data []byte) bool {
+	if hasExt(fileName, ".wsdl") {
+		return true
+	}
+	return hasExt(fileName, ".xml") && IsWSDL(data)
+}
+
+// Import resolves wsdl:import and xsd:import targets among the related
+// .wsdl, .xsd and .xml files
+func (wsdlImporter) Import(fileName string, data []byte, related map[string][]byte) (model.APIDocument, error) {
+	files := map[string][]byte{fileName: data}
+	for name, content := range related {
+		if hasExt(name, ".wsdl", ".xsd", ".xml") {
+			files[name] = content
+		}
+	}
+	wsdl, err := ParseWSDL(fileName, files)
+	if err != nil {
+		return model.APIDocument{}, err
+	}
+	return wsdlToDocument(wsdl), nil
+}
+
+// wsdlToDocument maps every binding operation to a POST endpoint, grouped by
+// port, with the SOAP envelope samples as its example
+func wsdlToDocument(wsdl model.WSDLDoc) model.APIDocument {
+	doc := model.APIDocument{
+		Name:        wsdl.Name,
+		Format:      wsdlImporter{}.Name(),
+		Description: wsdl.TargetNamespace,
+	}
+	for _, op := range wsdl.Operations {
+		contentType := "text/xml; charset=utf-8"
+		if op.SOAPVersion == "1.2" {
+			contentType = "application/soap+xml; charset=utf-8"
+		}
+		endpoint := model.APIEndpoint{
+			Name:        op.Name,
+			Kind:        "SOAP " + op.SOAPVersion,
+			Method:      "POST",
+			URL:         op.Address,
+			Description: op.Documentation,
+			Attributes: []model.APIAttribute{
+				{Key: "Service", Value: op.Service},
+				{Key: "Binding", Value: op.Binding},
+				{Key: "Style", Value: op.Style},
+				{Key: "SOAPAction", Value: op.SOAPAction},
+			},
+			Headers: []model.APIParameter{{Name: "Content-Type", In: "header", Example: contentType, Required: true}},
+			Body: &model.APIBody{
+				MediaType: contentType,
+				Mode:      "raw",
+				Fields:    bodyFields(op.Input),
+			},
+			Responses: []model.APIResponse{{
+				Status:      firstNonEmpty(op.OutputElement, "Response"),
+				Description: "SOAP response envelope",
+				Body: &model.APIBody{
+					MediaType: contentType,
+					Mode:      "raw",
+					Fields:    bodyFields(op.Output),
+				},
+			}},
+			Examples: []model.APIExample{{
+				Name:     "Sample envelope",
+				Request:  op.SampleRequest,
+				Response: op.SampleResponse,
+			}},
+		}
+		if op.SOAPVersion != "1.2" && op.SOAPAction != "" {
+			endpoint.Headers = append(endpoint.Headers, model.APIParameter{Name: "SOAPAction", In: "header", Example: fmt.Sprintf("%q", op.SOAPAction), Required: true})
+		}
+		if len(op.Faults) > 0 {
+			endpoint.Attributes = append(endpoint.Attributes, model.APIAttribute{Key: "Faults", Value: strings.Join(op.Faults, ", ")})
+		}
+		addEndpoint(&doc, firstNonEmpty(op.Port, op.Binding), endpoint)
+	}
+	return doc
+}
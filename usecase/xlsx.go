@@ -0,0 +1,270 @@
+package usecase
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/arifth/botthie/model"
+	"github.com/arifth/botthie/util"
+)
+
+var (
+	// xlsxSheetInvalid matches the characters Excel does not allow in sheet
+	// names
+	xlsxSheetInvalid = regexp.MustCompile(`[\[\]:*?/\\]`)
+	// xlsxNumber matches the counters written as numeric cells
+	xlsxNumber = regexp.MustCompile(`^[1-9][0-9]{0,8}$`)
+)
+
+// dictionaryColumns are the columns of a data dictionary sheet, the
+// consolidated sheet prefixes them with the endpoint columns
+var dictionaryColumns = []xlsxColumn{
+	{"No.", 6}, {"Location", 22}, {"Field", 30}, {"Type", 14}, {"Mandatory", 11}, {"Constraints", 30}, {"Description", 60},
+}
+
+var endpointColumns = []xlsxColumn{
+	{"Document", 24}, {"Service", 20}, {"Endpoint", 30}, {"Method", 9}, {"URL", 40},
+}
+
+type xlsxColumn struct {
+	title string
+	width float64
+}
+
+type xlsxSheet struct {
+	name    string
+	columns []xlsxColumn
+	rows    [][]string
+	// links points cells of the first column to other sheets
+	links map[int]string
+}
+
+// RenderXLSX renders the documents as an Excel data dictionary listing every
+// header, parameter and request and response body field. Each endpoint gets a
+// sheet linked from an overview, or with consolidated every field is listed
+// on a single sheet
+func (Usecase) RenderXLSX(docs []model.APIDocument, consolidated bool, uc *Usecase) ([]byte, error) {
+	names := xlsxSheetNames{}
+	overview := xlsxSheet{
+		name:    names.next("Overview"),
+		columns: append(append([]xlsxColumn{{"Sheet", 24}}, endpointColumns...), xlsxColumn{"Fields", 8}),
+		links:   map[int]string{},
+	}
+	all := xlsxSheet{
+		name:    names.next("Data Dictionary"),
+		columns: append(append([]xlsxColumn{}, endpointColumns...), dictionaryColumns...),
+	}
+	var sheets []xlsxSheet
+	for _, doc := range docs {
+		for _, service := range doc.Services {
+			for _, endpoint := range service.Endpoints {
+				rows := dictionaryRows(endpoint)
+				prefix := []string{doc.Name, service.Name, endpoint.Name, endpoint.Method, endpoint.URL}
+				if consolidated {
+					for _, row := range rows {
+						all.rows = append(all.rows, append(append([]string{}, prefix...), row...))
+					}
+					continue
+				}
+				sheet := xlsxSheet{name: names.next(endpoint.Name), columns: dictionaryColumns, rows: rows}
+				overview.links[len(overview.rows)] = sheet.name
+				overview.rows = append(overview.rows, append(append([]string{sheet.name}, prefix...), fmt.Sprint(len(rows))))
+				sheets = append(sheets, sheet)
+			}
+		}
+	}
+	if consolidated {
+		return writeXLSX([]xlsxSheet{all})
+	}
+	return writeXLSX(append([]xlsxSheet{overview}, sheets...))
+}
+
+// dictionaryRows lists the fields of an endpoint in request then response
+// order
+func dictionaryRows(endpoint model.APIEndpoint) [][]string {
+	var rows [][]string
+	addParam := func(location string, p model.APIParameter) {
+		rows = append(rows, []string{fmt.Sprint(len(rows) + 1), location, p.Name, p.Type, yesNo(p.Required), constraints(p.Default, p.Example), p.Description})
+	}
+	addField := func(location string, f model.APIField) {
+		rows = append(rows, []string{fmt.Sprint(len(rows) + 1), location, f.Name, f.Type, yesNo(f.Required), constraints(f.Default, ""), f.Description})
+	}
+	for _, h := range endpoint.Headers {
+		addParam("Request header", h)
+	}
+	for _, p := range endpoint.Parameters {
+		addParam(strings.TrimSpace(firstNonEmpty(p.In, "request")+" parameter"), p)
+	}
+	if endpoint.Body != nil {
+		for _, f := range endpoint.Body.Fields {
+			addField("Request body", f)
+		}
+	}
+	for _, r := range endpoint.Responses {
+		status := strings.TrimSpace("Response " + r.Status)
+		for _, h := range r.Headers {
+			addParam(status+" header", h)
+		}
+		if r.Body != nil {
+			for _, f := range r.Body.Fields {
+				addField(status+" body", f)
+			}
+		}
+	}
+	return rows
+}
+
+func constraints(defaultValue string, example string) string {
+	var res []string
+	if defaultValue != "" {
+		res = append(res, "default: "+defaultValue)
+	}
+	if example != "" {
+		res = append(res, "example: "+example)
+	}
+	return strings.Join(res, "; ")
+}
+
+// xlsxSheetNames hands out unique sheet names within Excel's 31 characters
+type xlsxSheetNames map[string]bool
+
+func (n xlsxSheetNames) next(name string) string {
+	name = strings.Trim(strings.Join(strings.Fields(xlsxSheetInvalid.ReplaceAllString(name, " ")), " "), "'")
+	base := []rune(firstNonEmpty(name, "Sheet"))
+	if len(base) > 31 {
+		base = base[:31]
+	}
+	res := string(base)
+	for i := 2; n[strings.ToLower(res)]; i++ {
+		suffix := fmt.Sprintf(" (%d)", i)
+		trimmed := base
+		if len(trimmed)+len(suffix) > 31 {
+			trimmed = trimmed[:31-len(suffix)]
+		}
+		res = string(trimmed) + suffix
+	}
+	n[strings.ToLower(res)] = true
+	return res
+}
+
+// writeXLSX writes a workbook with a bold, frozen and filterable header row
+// on every sheet
+func writeXLSX(sheets []xlsxSheet) ([]byte, error) {
+	files := map[string][]byte{
+		"_rels/.rels":   []byte(xlsxRootRels),
+		"xl/styles.xml": []byte(xlsxStyles),
+	}
+	var contentTypes, workbookSheets, workbookRels strings.Builder
+	for i, sheet := range sheets {
+		id := i + 1
+		contentTypes.WriteString(fmt.Sprintf(`<Override PartName="/xl/worksheets/sheet%d.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>`, id))
+		workbookSheets.WriteString(fmt.Sprintf(`<sheet name="%s" sheetId="%d" r:id="rId%d"/>`, xmlEscape(sheet.name), id, id))
+		workbookRels.WriteString(fmt.Sprintf(`<Relationship Id="rId%d" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet%d.xml"/>`, id, id))
+		files[fmt.Sprintf("xl/worksheets/sheet%d.xml", id)] = []byte(sheetXML(sheet))
+	}
+	workbookRels.WriteString(fmt.Sprintf(`<Relationship Id="rId%d" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>`, len(sheets)+1))
+	files["[Content_Types].xml"] = []byte(fmt.Sprintf(xlsxContentTypes, contentTypes.String()))
+	files["xl/workbook.xml"] = []byte(fmt.Sprintf(xlsxWorkbook, workbookSheets.String()))
+	files["xl/_rels/workbook.xml.rels"] = []byte(fmt.Sprintf(xlsxWorkbookRels, workbookRels.String()))
+	return util.WriteZip(files)
+}
+
+func sheetXML(sheet xlsxSheet) string {
+	var sb strings.Builder
+	last := xlsxCell(len(sheet.columns)-1, len(sheet.rows))
+	sb.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>`)
+	sb.WriteString(`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">`)
+	sb.WriteString(`<sheetViews><sheetView workbookViewId="0"><pane ySplit="1" topLeftCell="A2" activePane="bottomLeft" state="frozen"/></sheetView></sheetViews>`)
+	sb.WriteString(`<cols>`)
+	for i, col := range sheet.columns {
+		sb.WriteString(fmt.Sprintf(`<col min="%d" max="%d" width="%g" customWidth="1"/>`, i+1, i+1, col.width))
+	}
+	sb.WriteString(`</cols><sheetData>`)
+	header := make([]string, len(sheet.columns))
+	for i, col := range sheet.columns {
+		header[i] = col.title
+	}
+	writeRow := func(r int, cells []string, style int) {
+		sb.WriteString(fmt.Sprintf(`<row r="%d">`, r+1))
+		for c, value := range cells {
+			cellStyle := style
+			if _, ok := sheet.links[r-1]; ok && c == 0 && r > 0 {
+				cellStyle = 3
+			}
+			switch {
+			case value == "":
+				sb.WriteString(fmt.Sprintf(`<c r="%s" s="%d"/>`, xlsxCell(c, r), cellStyle))
+			case xlsxNumber.MatchString(value):
+				sb.WriteString(fmt.Sprintf(`<c r="%s" s="%d"><v>%s</v></c>`, xlsxCell(c, r), cellStyle, value))
+			default:
+				sb.WriteString(fmt.Sprintf(`<c r="%s" s="%d" t="inlineStr"><is><t xml:space="preserve">%s</t></is></c>`,
+					xlsxCell(c, r), cellStyle, xmlEscape(xlsxText(value))))
+			}
+		}
+		sb.WriteString(`</row>`)
+	}
+	writeRow(0, header, 1)
+	for i, row := range sheet.rows {
+		writeRow(i+1, row, 2)
+	}
+	sb.WriteString(`</sheetData>`)
+	sb.WriteString(fmt.Sprintf(`<autoFilter ref="A1:%s"/>`, last))
+	if len(sheet.links) > 0 {
+		sb.WriteString(`<hyperlinks>`)
+		for r := range sheet.rows {
+			if target, ok := sheet.links[r]; ok {
+				location := "'" + strings.ReplaceAll(target, "'", "''") + "'!A1"
+				sb.WriteString(fmt.Sprintf(`<hyperlink ref="%s" location="%s" display="%s"/>`, xlsxCell(0, r+1), xmlEscape(location), xmlEscape(target)))
+			}
+		}
+		sb.WriteString(`</hyperlinks>`)
+	}
+	sb.WriteString(`</worksheet>`)
+	return sb.String()
+}
+
+// xlsxCell returns the A1 reference of a zero based column and row
+func xlsxCell(col int, row int) string {
+	name := ""
+	for col++; col > 0; col = (col - 1) / 26 {
+		name = string(rune('A'+(col-1)%26)) + name
+	}
+	return fmt.Sprintf("%s%d", name, row+1)
+}
+
+// xlsxText keeps a value within the 32767 characters of an Excel cell
+func xlsxText(s string) string {
+	if runes := []rune(s); len(runes) > 32000 {
+		return string(runes[:32000]) + "..."
+	}
+	return s
+}
+
+// SendXLSX renders the documents as a data dictionary and sends it back to
+// the chat as a document named after name
+func (Usecase) SendXLSX(name string, docs []model.APIDocument, consolidated bool, uc *Usecase) error {
+	data, err := uc.RenderXLSX(docs, consolidated, uc)
+	if err != nil {
+		return err
+	}
+	caption := fmt.Sprintf("📊 Data dictionary of %s", name)
+	return uc.SendFile(slugify(name)+"-data-dictionary.xlsx", data, caption, uc)
+}
+
+const xlsxContentTypes = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types"><Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/><Default Extension="xml" ContentType="application/xml"/><Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/><Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/>%s</Types>`
+
+const xlsxRootRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/></Relationships>`
+
+const xlsxWorkbook = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets>%s</sheets></workbook>`
+
+const xlsxWorkbookRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">%s</Relationships>`
+
+// xlsxStyles holds the cell formats referenced by index: 0 default, 1 header,
+// 2 wrapped text and 3 link
+const xlsxStyles = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><fonts count="3"><font><sz val="11"/><name val="Calibri"/></font><font><b/><sz val="11"/><name val="Calibri"/></font><font><u/><sz val="11"/><color rgb="FF0563C1"/><name val="Calibri"/></font></fonts><fills count="3"><fill><patternFill patternType="none"/></fill><fill><patternFill patternType="gray125"/></fill><fill><patternFill patternType="solid"><fgColor rgb="FFDEEBFF"/><bgColor indexed="64"/></patternFill></fill></fills><borders count="2"><border><left/><right/><top/><bottom/><diagonal/></border><border><left style="thin"><color rgb="FFC1C7D0"/></left><right style="thin"><color rgb="FFC1C7D0"/></right><top style="thin"><color rgb="FFC1C7D0"/></top><bottom style="thin"><color rgb="FFC1C7D0"/></bottom><diagonal/></border></borders><cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs><cellXfs count="4"><xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0"/><xf numFmtId="0" fontId="1" fillId="2" borderId="1" xfId="0" applyFont="1" applyFill="1" applyBorder="1"/><xf numFmtId="0" fontId="0" fillId="0" borderId="1" xfId="0" applyBorder="1" applyAlignment="1"><alignment vertical="top" wrapText="1"/></xf><xf numFmtId="0" fontId="2" fillId="0" borderId="1" xfId="0" applyFont="1" applyBorder="1" applyAlignment="1"><alignment vertical="top"/></xf></cellXfs><cellStyles count="1"><cellStyle name="Normal" xfId="0" builtinId="0"/></cellStyles></styleSheet>`
@@ -3,8 +3,11 @@ package util
 import (
 	"math/rand"
 	"os"
+	"path/filepath"
 	"strings"
 	"time"
+
+	"github.com/arifth/botthie/model"
 )
 
 func GetDataFromTemplate(path string) (string, error) {
@@ -17,6 +20,25 @@ func GetDataFromTemplate(path string) (string, error) {
 	return string(cleansed), nil
 }
 
+// LoadTemplates reads every page template used by the renderers from dir
+func LoadTemplates(dir string) (model.Templates, error) {
+	var templ model.Templates
+	files := map[string]*string{
+		"apiBook.html":    &templ.APIBook,
+		"type.html":       &templ.Type,
+		"site.html":       &templ.Site,
+		"confluence.html": &templ.Confluence,
+	}
+	for name, dst := range files {
+		data, err := GetDataFromTemplate(filepath.Join(dir, name))
+		if err != nil {
+			return templ, err
+		}
+		*dst = data
+	}
+	return templ, nil
+}
+
 func GenerateRandomChars() string {
 	const charset = "abcdefghijklmnopqrstuvwxyz123456789"
 
@@ -3,9 +3,22 @@ package util
 import "github.com/arifth/botthie/model"
 
 func Validate(collection model.PostmanCollection) bool {
-	// valid template only consist of one level
-	for _, item := range collection.Item {
-		if item.Name == "" || item.Request.Method == "" || item.Request.URL == nil || item.Request.Header == nil {
+	return validItems(collection.Item)
+}
+
+// validItems checks the requests of a collection, descending into folders
+func validItems(items []model.PostmanItem) bool {
+	for _, item := range items {
+		if item.Name == "" {
+			return false
+		}
+		if item.Item != nil {
+			if !validItems(item.Item) {
+				return false
+			}
+			continue
+		}
+		if item.Request.Method == "" || item.Request.URL == nil || item.Request.Header == nil {
 			return false
 		}
 	}
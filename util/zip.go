@@ -0,0 +1,92 @@
+package util
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// Limits of the zip archives read from chat uploads, so a zip bomb cannot
+// exhaust the memory of the bot
+const (
+	MaxZipEntries   = 1000
+	MaxZipEntrySize = 32 << 20
+	MaxZipSize      = 128 << 20
+)
+
+// ReadZip returns the content of every regular file in a zip archive keyed by
+// its path inside the archive. It fails when the archive holds more than
+// MaxZipEntries files, a file larger than MaxZipEntrySize or more than
+// MaxZipSize bytes in total once uncompressed
+func ReadZip(data []byte) (map[string][]byte, error) {
+	reader, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, err
+	}
+
+	files := map[string][]byte{}
+	var total int64
+	for _, f := range reader.File {
+		if f.FileInfo().IsDir() || strings.HasPrefix(f.Name, "__MACOSX/") {
+			continue
+		}
+		if len(files) == MaxZipEntries {
+			return nil, fmt.Errorf("zip archive has more than %d files", MaxZipEntries)
+		}
+		content, err := readZipFile(f, min(MaxZipEntrySize, MaxZipSize-total))
+		if err != nil {
+			return nil, err
+		}
+		total += int64(len(content))
+		files[f.Name] = content
+	}
+	return files, nil
+}
+
+// readZipFile reads a file of a zip archive, failing once more than limit
+// bytes come out of it whatever size its header declares
+func readZipFile(f *zip.File, limit int64) ([]byte, error) {
+	rc, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	content, err := io.ReadAll(io.LimitReader(rc, limit+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(content)) > limit {
+		return nil, fmt.Errorf("%s is too large once uncompressed, the limits are %d MB per file and %d MB per archive",
+			f.Name, MaxZipEntrySize>>20, MaxZipSize>>20)
+	}
+	return content, nil
+}
+
+// WriteZip builds a zip archive from file contents keyed by their path,
+// writing the entries in path order
+func WriteZip(files map[string][]byte) ([]byte, error) {
+	var names []string
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var buf bytes.Buffer
+	writer := zip.NewWriter(&buf)
+	for _, name := range names {
+		w, err := writer.Create(name)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := w.Write(files[name]); err != nil {
+			return nil, err
+		}
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
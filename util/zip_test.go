@@ -0,0 +1,59 @@
+package util
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestReadZip(t *testing.T) {
+	archive, err := WriteZip(map[string][]byte{
+		"api.json":          []byte(`{"info":{}}`),
+		"env/dev.json":      []byte(`{"values":[]}`),
+		"__MACOSX/api.json": []byte("resource fork"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	files, err := ReadZip(archive)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 2 || string(files["api.json"]) != `{"info":{}}` || string(files["env/dev.json"]) != `{"values":[]}` {
+		t.Errorf("ReadZip = %q", files)
+	}
+}
+
+func TestReadZipLimits(t *testing.T) {
+	tooMany := map[string][]byte{}
+	for i := 0; i <= MaxZipEntries; i++ {
+		tooMany[fmt.Sprintf("f%d.json", i)] = nil
+	}
+	tooLarge := map[string][]byte{"bomb.json": bytes.Repeat([]byte{'0'}, MaxZipEntrySize+1)}
+	tooLargeTotal := map[string][]byte{}
+	for i := 0; i <= MaxZipSize/MaxZipEntrySize; i++ {
+		tooLargeTotal[fmt.Sprintf("part%d.json", i)] = bytes.Repeat([]byte{'0'}, MaxZipEntrySize)
+	}
+
+	tests := []struct {
+		name  string
+		files map[string][]byte
+		want  string
+	}{
+		{"too many files", tooMany, "more than"},
+		{"file too large", tooLarge, "bomb.json is too large"},
+		{"archive too large", tooLargeTotal, "too large"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			archive, err := WriteZip(tt.files)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := ReadZip(archive); err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("ReadZip error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}